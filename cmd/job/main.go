@@ -1,142 +1,932 @@
-package main
-
-import (
-	"context"
-	"log"
-	"os"
-	"strconv"
-	"strings"
-
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
-
-	"wheretoeat/internal/adapter/api"
-	"wheretoeat/internal/adapter/repository/mongodb"
-	"wheretoeat/internal/adapter/repository/postgres"
-	"wheretoeat/internal/adapter/storage"
-	"wheretoeat/internal/adapter/util"
-	"wheretoeat/internal/adapter/fetch"
-)
-
-func main() {
-	util.LoadEnv()
-
-	if len(os.Args) < 2 {
-		log.Fatal("Usage: main <job_name> [<args>...]")
-	}
-
-	jobName := os.Args[1]
-	args := os.Args[2:]
-
-	switch strings.ToLower(jobName) {
-	case "run-fetch-places":
-		if len(args) < 5 {
-			log.Fatal("Usage: fetch_places <minLat> <maxLat> <minLng> <maxLng> <category>")
-		}
-
-		minLat, err := strconv.ParseFloat(args[0], 64)
-		if err != nil {
-			log.Fatalf("Invalid minLat: %v", err)
-		}
-
-		maxLat, err := strconv.ParseFloat(args[1], 64)
-		if err != nil {
-			log.Fatalf("Invalid maxLat: %v", err)
-		}
-
-		minLng, err := strconv.ParseFloat(args[2], 64)
-		if err != nil {
-			log.Fatalf("Invalid minLng: %v", err)
-		}
-
-		maxLng, err := strconv.ParseFloat(args[3], 64)
-		if err != nil {
-			log.Fatalf("Invalid maxLng: %v", err)
-		}
-
-		category := args[4]
-
-		log.Printf("Fetching places for %s in area (%f-%f, %f-%f)", category, minLat, maxLat, minLng, maxLng)
-		
-		client, err := mongodb.NewMongoAdapter()
-		if err != nil {
-			log.Fatalf("Failed to initialize MongoDB client: %v", err)
-		}
-		defer client.Disconnect(context.TODO())
-
-		placesRepo := mongodb.NewPlacesRepo(client)
-		categoriesRepo := mongodb.NewCategoriesRepo(client)
-		apiAdapter := api.NewNearbySearchAPI()
-
-		service := fetch.NewFetchPlacesService(placesRepo, categoriesRepo, apiAdapter)
-		err = service.FetchPlaces(context.TODO(), minLat, maxLat, minLng, maxLng, category)
-		if err != nil {
-			log.Fatalf("Failed to fetch places: %v", err)
-		}
-
-		log.Println("Fetch places job completed successfully.")
-
-	case "run-fetch-images":
-		if len(args) < 2 {
-			log.Fatal("Usage: fetch_images <limit> <offset> (limit and offset of raw places responses, each response contains multiple places)")
-		}
-
-		limit, err := strconv.Atoi(args[0])
-		if err != nil {
-			log.Fatalf("Invalid limit: %v", err)
-		}
-
-		offset, err := strconv.Atoi(args[1])
-		if err != nil {
-			log.Fatalf("Invalid offset: %v", err)
-		}
-
-		log.Printf("Fetching images with limit %d and offset %d", limit, offset)
-
-		// PostgreSQL connection
-		pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
-		if err != nil {
-			log.Fatalf("Failed to connect to PostgreSQL: %v", err)
-		}
-		defer pgDB.Close()
-
-		localUploader := storage.NewLocalUploader()
-		placesRepo := postgres.NewPlacesRepo(pgDB)
-
-		service := fetch.NewFetchImagesService(localUploader, placesRepo)
-		err = service.FetchImages(context.TODO(), limit, offset)
-		if err != nil {
-			log.Fatalf("Failed to fetch images: %v", err)
-		}
-
-		log.Println("Fetch images job completed successfully.")
-
-	case "run-fetch-areas":
-		if len(args) < 1 {
-			log.Fatal("Usage: fetch_areas <query>")
-		}
-
-		query := args[0]
-		log.Printf("Fetching areas for query '%s'", query)
-
-		client, err := mongodb.NewMongoAdapter()
-		if err != nil {
-			log.Fatalf("Failed to initialize MongoDB client: %v", err)
-		}
-		defer client.Disconnect(context.TODO())
-
-		areasRepo := mongodb.NewAreasRepo(client)
-		apiAdapter := api.NewTextSearchAPI()
-
-		service := fetch.NewFetchAreasService(areasRepo, apiAdapter)
-		err = service.FetchAreas(context.TODO(), query)
-		if err != nil {
-			log.Fatalf("Failed to fetch areas: %v", err)
-		}
-
-		log.Println("Fetch areas job completed successfully.")
-
-	default:
-		log.Fatalf("Unknown job: %s", jobName)
-	}
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"wheretoeat/internal/adapter/analysis"
+	"wheretoeat/internal/adapter/api"
+	"wheretoeat/internal/adapter/budget"
+	"wheretoeat/internal/adapter/repository/mongodb"
+	"wheretoeat/internal/adapter/repository/postgres"
+	"wheretoeat/internal/adapter/storage"
+	"wheretoeat/internal/adapter/util"
+	"wheretoeat/internal/adapter/fake"
+	"wheretoeat/internal/adapter/fetch"
+	"wheretoeat/internal/adapter/geojson"
+	"wheretoeat/internal/adapter/osm"
+	"wheretoeat/internal/adapter/pipeline/mongo2postgres"
+	"wheretoeat/internal/adapter/ratelimit"
+	"wheretoeat/internal/adapter/service"
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+
+func main() {
+	util.LoadEnv()
+
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: main <job_name> [<args>...]")
+	}
+
+	jobName := os.Args[1]
+	args := os.Args[2:]
+
+	// Cancelled on Ctrl-C, SIGTERM or JOB_TIMEOUT: jobs stop starting new work and log what they finished
+	ctx, cancel := util.JobContext()
+	defer cancel()
+
+	switch strings.ToLower(jobName) {
+	case "run-fetch-places":
+		runFetchPlaces(ctx, args)
+
+	case "run-fetch-text":
+		runFetchText(ctx, args)
+
+	case "run-refresh":
+		runRefresh(ctx, args)
+
+	case "run-refresh-place-details":
+		runRefreshPlaceDetails(ctx, args)
+
+	case "run-export-coverage":
+		runExportCoverage(ctx, args)
+
+	case "run-fetch-images":
+		if len(args) < 2 {
+			log.Fatal("Usage: fetch_images <limit> <offset> (limit and offset of raw places responses, each response contains multiple places)")
+		}
+
+		limit, err := strconv.Atoi(args[0])
+		if err != nil {
+			log.Fatalf("Invalid limit: %v", err)
+		}
+
+		offset, err := strconv.Atoi(args[1])
+		if err != nil {
+			log.Fatalf("Invalid offset: %v", err)
+		}
+
+		log.Printf("Fetching images with limit %d and offset %d", limit, offset)
+
+		// PostgreSQL connection
+		pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
+		if err != nil {
+			log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		}
+		defer pgDB.Close()
+
+		localUploader := storage.NewLocalUploader()
+		placesRepo := postgres.NewPlacesRepo(pgDB)
+
+		service := fetch.NewFetchImagesService(localUploader, placesRepo)
+		err = service.FetchImages(ctx, limit, offset)
+		if ctx.Err() != nil {
+			break // The service logged what finished
+		}
+		if err != nil {
+			log.Fatalf("Failed to fetch images: %v", err)
+		}
+
+		log.Println("Fetch images job completed successfully.")
+
+	case "run-fetch-areas":
+		if len(args) < 1 {
+			log.Fatal("Usage: fetch_areas <query>")
+		}
+
+		query := args[0]
+		log.Printf("Fetching areas for query '%s'", query)
+
+		client, err := mongodb.NewMongoAdapter()
+		if err != nil {
+			log.Fatalf("Failed to initialize MongoDB client: %v", err)
+		}
+		defer client.Disconnect(context.TODO())
+
+		areasRepo := mongodb.NewAreasRepo(client)
+		apiBudget := newBudget(ctx, client)
+		setRateLimit(rateLimitsFromEnv())
+		apiAdapter := budget.NewBudgetedPlacesAPI(api.NewTextSearchAPI(domain.FieldMaskBasic), apiBudget, domain.FieldMaskBasic.TextSearchSKU)
+
+		service := fetch.NewFetchAreasService(areasRepo, apiAdapter)
+		err = service.FetchAreas(ctx, query)
+		if err != nil {
+			log.Fatalf("Failed to fetch areas: %v", err)
+		}
+		logBudgetUsage(apiBudget)
+
+		log.Println("Fetch areas job completed successfully.")
+
+	case "run-import-osm":
+		if len(args) < 1 {
+			log.Fatal("Usage: import_osm <extract.pbf|overpass.json> [--fallback-category <category>]")
+		}
+
+		flags := flag.NewFlagSet("run-import-osm", flag.ExitOnError)
+		fallbackCategory := flags.String("fallback-category", "", "category for places whose tags match no configured category")
+		flags.Parse(args[1:])
+
+		log.Printf("Importing OpenStreetMap places from %s", args[0])
+
+		client, err := mongodb.NewMongoAdapter()
+		if err != nil {
+			log.Fatalf("Failed to initialize MongoDB client: %v", err)
+		}
+		defer client.Disconnect(context.TODO())
+
+		placesRepo := mongodb.NewPlacesRepo(client)
+		categoriesRepo := mongodb.NewCategoriesRepo(client)
+
+		service := osm.NewImportOSMService(placesRepo, categoriesRepo)
+		err = service.ImportOSM(ctx, args[0], *fallbackCategory)
+		if err != nil {
+			log.Fatalf("Failed to import OSM places: %v", err)
+		}
+
+		log.Println("Import OSM job completed successfully. Run the ETL to load the places into PostgreSQL.")
+
+	case "run-generate-fake-places":
+		if len(args) < 5 {
+			log.Fatal("Usage: generate_fake_places <lat> <lng> <radius> <count> <output.json> [--types <type,...>] [--seed <n>]")
+		}
+
+		flags := flag.NewFlagSet("run-generate-fake-places", flag.ExitOnError)
+		types := flags.String("types", "restaurant,cafe,vietnamese_restaurant", "comma-separated primary types to draw from")
+		seed := flags.Int64("seed", 1, "random seed, the same seed gives the same dataset")
+		flags.Parse(args[5:])
+
+		var area domain.Circle
+		var err error
+		if area.Lat, err = strconv.ParseFloat(args[0], 64); err != nil {
+			log.Fatalf("Invalid lat: %v", err)
+		}
+		if area.Lng, err = strconv.ParseFloat(args[1], 64); err != nil {
+			log.Fatalf("Invalid lng: %v", err)
+		}
+		if area.Radius, err = strconv.ParseFloat(args[2], 64); err != nil {
+			log.Fatalf("Invalid radius: %v", err)
+		}
+		count, err := strconv.Atoi(args[3])
+		if err != nil {
+			log.Fatalf("Invalid count: %v", err)
+		}
+
+		places := fake.GeneratePlaces(*seed, count, area, strings.Split(*types, ","))
+		if err := fake.WritePlaces(args[4], places); err != nil {
+			log.Fatalf("Failed to write fake places: %v", err)
+		}
+		log.Printf("Wrote %d fake places to %s", len(places), args[4])
+
+	case "run-list-areas":
+		client, err := mongodb.NewMongoAdapter()
+		if err != nil {
+			log.Fatalf("Failed to initialize MongoDB client: %v", err)
+		}
+		defer client.Disconnect(context.TODO())
+
+		areasRepo := mongodb.NewAreasRepo(client)
+		var areas []domain.Area
+		if len(args) > 0 {
+			areas, err = areasRepo.SearchAreas(ctx, args[0])
+		} else {
+			areas, err = areasRepo.ListAreas(ctx)
+		}
+		if err != nil {
+			log.Fatalf("Failed to list areas: %v", err)
+		}
+
+		for _, area := range areas {
+			log.Printf("%s (%s, query '%s', parent '%s'): %f %f %f %f", area.Name, area.Level, area.Query, area.ParentID, area.MinLat, area.MaxLat, area.MinLng, area.MaxLng)
+		}
+		log.Printf("Found %d areas.", len(areas))
+
+	case "run-set-area-boundary":
+		if len(args) < 2 {
+			log.Fatal("Usage: set_area_boundary <area-name> <file.geojson>")
+		}
+
+		boundary, err := geojson.LoadBoundary(args[1])
+		if err != nil {
+			log.Fatalf("Failed to load boundary: %v", err)
+		}
+
+		client, err := mongodb.NewMongoAdapter()
+		if err != nil {
+			log.Fatalf("Failed to initialize MongoDB client: %v", err)
+		}
+		defer client.Disconnect(context.TODO())
+
+		areasRepo := mongodb.NewAreasRepo(client)
+		area, err := areasRepo.GetAreaByName(ctx, args[0])
+		if err != nil {
+			log.Fatalf("Failed to look up area: %v", err)
+		}
+		if area == nil {
+			log.Fatalf("Area '%s' not found, fetch it first with run-fetch-areas", args[0])
+		}
+
+		err = areasRepo.SetAreaBoundary(ctx, area.PlaceID, boundary)
+		if err != nil {
+			log.Fatalf("Failed to set area boundary: %v", err)
+		}
+
+		log.Printf("Stored a boundary of %d polygons for %s.", len(boundary), area.Name)
+
+	case "run-import-areas":
+		if len(args) < 1 {
+			log.Fatal("Usage: import_areas <file.geojson>")
+		}
+
+		areas, skipped, err := geojson.LoadAreas(args[0])
+		if err != nil {
+			log.Fatalf("Failed to load areas: %v", err)
+		}
+		if skipped > 0 {
+			log.Printf("Skipping %d features without a polygon, a name or a level", skipped)
+		}
+
+		client, err := mongodb.NewMongoAdapter()
+		if err != nil {
+			log.Fatalf("Failed to initialize MongoDB client: %v", err)
+		}
+		defer client.Disconnect(context.TODO())
+
+		service := fetch.NewImportAreasService(mongodb.NewAreasRepo(client))
+		if err := service.ImportAreas(ctx, areas); err != nil {
+			log.Fatalf("Failed to import areas: %v", err)
+		}
+
+		log.Printf("Imported %d areas. Run the ETL to assign places to them.", len(areas))
+
+	case "run-analyze-reviews":
+		log.Println("Analyzing reviews")
+
+		// PostgreSQL connection
+		pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
+		if err != nil {
+			log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		}
+		defer pgDB.Close()
+
+		reviewsRepo := postgres.NewReviewsRepo(pgDB)
+
+		service := analysis.NewReviewAnalysisService(reviewsRepo)
+		err = service.AnalyzeReviews(ctx)
+		if err != nil {
+			log.Fatalf("Failed to analyze reviews: %v", err)
+		}
+
+		log.Println("Analyze reviews job completed successfully.")
+
+	case "run-extract-dishes":
+		log.Println("Extracting dish mentions")
+
+		// PostgreSQL connection
+		pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
+		if err != nil {
+			log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+		}
+		defer pgDB.Close()
+
+		placesRepo := postgres.NewPlacesRepo(pgDB)
+		reviewsRepo := postgres.NewReviewsRepo(pgDB)
+		dishesRepo := postgres.NewDishesRepo(pgDB)
+
+		service := analysis.NewDishExtractionService(placesRepo, reviewsRepo, dishesRepo)
+		err = service.ExtractDishes(ctx)
+		if err != nil {
+			log.Fatalf("Failed to extract dishes: %v", err)
+		}
+
+		log.Println("Extract dishes job completed successfully.")
+
+	default:
+		log.Fatalf("Unknown job: %s", jobName)
+	}
+
+	// Jobs stopped early return after logging their progress, but must not exit cleanly
+	if err := ctx.Err(); err != nil {
+		log.Fatalf("Job %s stopped before finishing: %v", jobName, err)
+	}
+}
+
+// runFetchPlaces crawls places in a bounding box, a saved area, or resumes a crawl job:
+//
+//	run-fetch-places <minLat> <maxLat> <minLng> <maxLng> <category>
+//	run-fetch-places --area <name> (--category <category> | --all-categories) [--boundary <file.geojson>]
+//	run-fetch-places --boundary <file.geojson> (--category <category> | --all-categories)
+//	run-fetch-places --resume <jobID>
+//
+// With --dry-run it only prints the planned crawl and its estimated cost, optionally
+// exporting the planned circles with --plan-output, and sends nothing to Google.
+func runFetchPlaces(ctx context.Context, args []string) {
+	usage := "Usage: fetch_places <minLat> <maxLat> <minLng> <maxLng> <category> | " +
+		"fetch_places (--area <name> | --boundary <file.geojson>) (--category <category> | --all-categories) | fetch_places --resume <jobID>"
+
+	flags := flag.NewFlagSet("run-fetch-places", flag.ExitOnError)
+	resumeJobID := flags.String("resume", "", "ID of the crawl job to resume")
+	areaName := flags.String("area", "", "name of an area saved by run-fetch-areas")
+	category := flags.String("category", "", "category to crawl")
+	allCategories := flags.Bool("all-categories", false, "crawl every configured category")
+	boundaryPath := flags.String("boundary", "", "GeoJSON polygon to limit the crawl to, defaults to the area's stored boundary")
+	dryRun := flags.Bool("dry-run", false, "plan the crawl and estimate its cost without calling the Places API")
+	planOutput := flags.String("plan-output", "", "GeoJSON file to write the planned circles to, with --dry-run")
+	fields := flags.String("fields", domain.FieldMaskFull.Name, "field mask profile: basic, contact, atmosphere or full")
+	fakePlaces := flags.String("fake-places", "", "serve the crawl from a places dataset or recording instead of the Places API")
+	record := flags.String("record", "", "append every Places API request and response to this file")
+	grid := addGridFlags(flags)
+	rateLimits := addRateLimitFlags(flags)
+
+	var minLat, maxLat, minLng, maxLng float64
+	var boundary domain.Boundary
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		flags.Parse(args)
+		switch {
+		case *resumeJobID != "":
+		case *areaName == "" && *boundaryPath == "":
+			log.Fatal(usage)
+		case (*category == "") == !*allCategories:
+			log.Fatal("Pass exactly one of --category or --all-categories")
+		}
+	} else {
+		if len(args) < 5 {
+			log.Fatal(usage)
+		}
+		minLat, maxLat, minLng, maxLng = parseBoundingBox(args[:4])
+		*category = args[4]
+		flags.Parse(args[5:])
+	}
+	if *dryRun && *resumeJobID != "" {
+		log.Fatal("--dry-run plans new crawls only, it can't be combined with --resume")
+	}
+	if *resumeJobID != "" && isFlagSet(flags, "fields") {
+		log.Fatal("--resume crawls with the job's own field mask, it can't be combined with --fields")
+	}
+	if *fakePlaces != "" && *record != "" {
+		log.Fatal("--record records the Places API, it can't be combined with --fake-places")
+	}
+	if err := grid.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	fieldMask, err := domain.FieldMaskByName(*fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *boundaryPath != "" {
+		var err error
+		boundary, err = geojson.LoadBoundary(*boundaryPath)
+		if err != nil {
+			log.Fatalf("Failed to load boundary: %v", err)
+		}
+	}
+
+	client, err := mongodb.NewMongoAdapter()
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB client: %v", err)
+	}
+	defer client.Disconnect(context.TODO())
+
+	placesRepo := mongodb.NewPlacesRepo(client)
+	if err := placesRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to prepare search results collection: %v", err)
+	}
+	categoriesRepo := mongodb.NewCategoriesRepo(client)
+	crawlJobsRepo := mongodb.NewCrawlJobsRepo(client)
+
+	if *resumeJobID != "" {
+		// A resumed crawl keeps requesting the fields the job was started with
+		job, err := crawlJobsRepo.GetJob(ctx, *resumeJobID)
+		if err != nil {
+			log.Fatalf("Failed to load crawl job: %v", err)
+		}
+		if job == nil {
+			log.Fatalf("Crawl job %s not found", *resumeJobID)
+		}
+		fieldMask, err = job.FieldMaskProfile()
+		if err != nil {
+			log.Fatalf("Failed to resume crawl job %s: %v", *resumeJobID, err)
+		}
+	}
+
+	// A dry run gets no API adapter at all, so nothing can reach Google
+	var apiAdapter port.PlacesAPIPort
+	switch {
+	case *dryRun:
+	case *fakePlaces != "":
+		places, err := fake.LoadPlaces(*fakePlaces)
+		if err != nil {
+			log.Fatalf("Failed to load fake places: %v", err)
+		}
+		log.Printf("Serving the crawl from %d fake places in %s, the Places API won't be called", len(places), *fakePlaces)
+		apiAdapter = fake.NewFakePlacesAPI(fieldMask, places)
+	default:
+		setRateLimit(*rateLimits)
+		apiBudget := newBudget(ctx, client)
+		apiAdapter = budget.NewBudgetedPlacesAPI(api.NewNearbySearchAPI(fieldMask), apiBudget, fieldMask.NearbySearchSKU)
+		defer logBudgetUsage(apiBudget)
+		if *record != "" {
+			recorder, err := fake.NewRecordingPlacesAPI(apiAdapter, *record)
+			if err != nil {
+				log.Fatalf("Failed to start recording: %v", err)
+			}
+			defer recorder.Close()
+			apiAdapter = recorder
+		}
+	}
+
+	if *resumeJobID != "" {
+		log.Printf("Resuming crawl job %s", *resumeJobID)
+		service := fetch.NewFetchPlacesService(placesRepo, categoriesRepo, crawlJobsRepo, apiAdapter)
+		report, err := service.ResumeFetchPlaces(ctx, *resumeJobID)
+		logCrawlReport(report, err)
+		return
+	}
+
+	if *areaName != "" {
+		area := loadArea(ctx, client, *areaName)
+		minLat, maxLat, minLng, maxLng = area.MinLat, area.MaxLat, area.MinLng, area.MaxLng
+		if boundary == nil {
+			boundary = area.Boundary
+		}
+	}
+	if boundary != nil {
+		// The viewport is wider than the real outline, crawl only the polygon's box
+		minLat, maxLat, minLng, maxLng = boundary.BoundingBox()
+		log.Printf("Limiting the crawl to a boundary of %d polygons", len(boundary))
+	}
+
+	categories := []string{*category}
+	if *allCategories {
+		categories, err = categoriesRepo.ListCategories(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list categories: %v", err)
+		}
+	}
+
+	if *dryRun {
+		service := fetch.NewFetchPlacesService(placesRepo, categoriesRepo, crawlJobsRepo, apiAdapter)
+		planFetchPlaces(ctx, service, minLat, maxLat, minLng, maxLng, boundary, *grid, fieldMask, categories, *planOutput)
+		return
+	}
+
+	for _, c := range categories {
+		log.Printf("Fetching places for %s in area (%f-%f, %f-%f)", c, minLat, maxLat, minLng, maxLng)
+		// Each crawl keeps its own progress, so every category gets a fresh service
+		service := fetch.NewFetchPlacesService(placesRepo, categoriesRepo, crawlJobsRepo, apiAdapter)
+		report, err := service.FetchPlaces(ctx, minLat, maxLat, minLng, maxLng, boundary, *grid, c)
+		logCrawlReport(report, err)
+		if ctx.Err() != nil {
+			return
+		}
+		if report.BudgetExhausted {
+			log.Printf("Skipping the remaining categories, budget exhausted")
+			return
+		}
+	}
+}
+
+// runFetchText crawls an area with a Text Search query
+func runFetchText(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("run-fetch-text", flag.ExitOnError)
+	areaName := flags.String("area", "", "name of an area saved by run-fetch-areas")
+	boundaryPath := flags.String("boundary", "", "GeoJSON polygon to limit the search to, defaults to the area's stored boundary")
+	query := flags.String("query", "", "text to search for, e.g. a dish name")
+	category := flags.String("category", "", "category to file the places under, optional")
+	fields := flags.String("fields", domain.FieldMaskFull.Name, "field mask profile: basic, contact, atmosphere or full")
+	grid := addGridFlags(flags)
+	rateLimits := addRateLimitFlags(flags)
+	flags.Parse(args)
+
+	if *query == "" || (*areaName == "" && *boundaryPath == "") {
+		log.Fatal("Usage: fetch_text (--area <name> | --boundary <file.geojson>) --query <text> [--category <category>] [--fields <profile>]")
+	}
+	if err := grid.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	fieldMask, err := domain.FieldMaskByName(*fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var boundary domain.Boundary
+	if *boundaryPath != "" {
+		boundary, err = geojson.LoadBoundary(*boundaryPath)
+		if err != nil {
+			log.Fatalf("Failed to load boundary: %v", err)
+		}
+	}
+
+	client, err := mongodb.NewMongoAdapter()
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB client: %v", err)
+	}
+	defer client.Disconnect(context.TODO())
+
+	var minLat, maxLat, minLng, maxLng float64
+	if *areaName != "" {
+		area := loadArea(ctx, client, *areaName)
+		minLat, maxLat, minLng, maxLng = area.MinLat, area.MaxLat, area.MinLng, area.MaxLng
+		if boundary == nil {
+			boundary = area.Boundary
+		}
+	}
+	if boundary != nil {
+		minLat, maxLat, minLng, maxLng = boundary.BoundingBox()
+	}
+
+	setRateLimit(*rateLimits)
+	apiBudget := newBudget(ctx, client)
+	apiAdapter := budget.NewBudgetedTextSearchAPI(api.NewTextSearchAPI(fieldMask), apiBudget, fieldMask.TextSearchSKU)
+	defer logBudgetUsage(apiBudget)
+
+	service := fetch.NewFetchTextService(mongodb.NewPlacesRepo(client), apiAdapter)
+	report, err := service.FetchText(ctx, minLat, maxLat, minLng, maxLng, boundary, *grid, *query, *category)
+	if report != nil && report.Interrupted {
+		log.Printf("Stopped after %d/%d cells, run the same search again to continue", report.CompletedCells, report.TotalCells)
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to search '%s': %v", *query, err)
+	}
+	if report.BudgetExhausted {
+		log.Printf("Budget exhausted after %d/%d cells, run the same search again to continue", report.CompletedCells, report.TotalCells)
+		return
+	}
+	log.Println("Fetch text job completed successfully. Run the ETL to load the places into PostgreSQL.")
+}
+
+// runRefresh re-queries the circles fetched longest ago and loads the changes into PostgreSQL
+func runRefresh(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("run-refresh", flag.ExitOnError)
+	category := flags.String("category", "", "only refresh circles of this category, defaults to every category")
+	olderThan := flags.String("older-than", "30d", "refresh circles last fetched longer ago than this, e.g. 30d or 12h")
+	limit := flags.Int("limit", 100, "maximum number of circles to refresh, oldest first")
+	fields := flags.String("fields", domain.FieldMaskFull.Name, "refresh circles crawled with this field mask profile")
+	skipETL := flags.Bool("skip-etl", false, "don't run the ETL after refreshing")
+	rateLimits := addRateLimitFlags(flags)
+	flags.Parse(args)
+
+	ttl, err := parseAge(*olderThan)
+	if err != nil {
+		log.Fatalf("Invalid --older-than: %v", err)
+	}
+	fieldMask, err := domain.FieldMaskByName(*fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := mongodb.NewMongoAdapter()
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB client: %v", err)
+	}
+	defer client.Disconnect(context.TODO())
+
+	placesRepo := mongodb.NewPlacesRepo(client)
+	if err := placesRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to prepare search results collection: %v", err)
+	}
+	categoriesRepo := mongodb.NewCategoriesRepo(client)
+
+	setRateLimit(*rateLimits)
+	apiBudget := newBudget(ctx, client)
+	apiAdapter := budget.NewBudgetedPlacesAPI(api.NewNearbySearchAPI(fieldMask), apiBudget, fieldMask.NearbySearchSKU)
+	defer logBudgetUsage(apiBudget)
+
+	service := fetch.NewRefreshPlacesService(placesRepo, categoriesRepo, apiAdapter)
+	report, err := service.RefreshPlaces(ctx, *category, time.Now().Add(-ttl), *limit)
+	if ctx.Err() != nil {
+		// The refreshed circles are saved, the next run with ETL loads them
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to refresh places: %v", err)
+	}
+	if report.BudgetExhausted {
+		log.Printf("Budget exhausted, the remaining stale circles are left for the next run")
+	}
+	if report.Refreshed == 0 || *skipETL {
+		return
+	}
+
+	pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer pgDB.Close()
+
+	etlService := pipeline.NewPlacesETLService(placesRepo, mongodb.NewAreasRepo(client), postgres.NewPlacesRepo(pgDB))
+	if err := etlService.SearchResultsToPostgres(ctx); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Fatalf("ETL pipeline failed: %v", err)
+	}
+	log.Println("Refresh job completed successfully.")
+}
+
+// runRefreshPlaceDetails fetches the given places, or the most viewed ones, with
+// Place Details and upserts them into PostgreSQL
+func runRefreshPlaceDetails(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("run-refresh-place-details", flag.ExitOnError)
+	topViewed := flags.Int("top-viewed", 0, "refresh the N most viewed places instead of the given IDs")
+	fields := flags.String("fields", domain.FieldMaskFull.Name, "field mask profile: basic, contact, atmosphere or full")
+	rateLimits := addRateLimitFlags(flags)
+	flags.Parse(args)
+
+	placeIDs := flags.Args()
+	if (len(placeIDs) == 0) == (*topViewed == 0) {
+		log.Fatal("Usage: refresh_place_details [--fields <profile>] (<placeID>... | --top-viewed <n>)")
+	}
+	fieldMask, err := domain.FieldMaskByName(*fields)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := mongodb.NewMongoAdapter()
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB client: %v", err)
+	}
+	defer client.Disconnect(context.TODO())
+
+	pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer pgDB.Close()
+
+	setRateLimit(*rateLimits)
+	apiBudget := newBudget(ctx, client)
+	detailsAPI := budget.NewBudgetedPlaceDetailsAPI(api.NewPlaceDetailsAPI(fieldMask), apiBudget, fieldMask.PlaceDetailsSKU)
+	defer logBudgetUsage(apiBudget)
+
+	service := fetch.NewRefreshPlaceDetailsService(postgres.NewPlacesRepo(pgDB), detailsAPI)
+	var refreshed int
+	if *topViewed > 0 {
+		refreshed, err = service.RefreshMostViewed(ctx, *topViewed)
+	} else {
+		refreshed, err = service.RefreshPlaceDetails(ctx, placeIDs)
+	}
+	if ctx.Err() != nil {
+		log.Printf("Stopped after refreshing %d places", refreshed)
+		return
+	}
+	if errors.Is(err, domain.ErrBudgetExhausted) {
+		log.Printf("Budget exhausted, the remaining places were not refreshed")
+		return
+	}
+	if err != nil {
+		log.Fatalf("Failed to refresh place details: %v", err)
+	}
+	log.Println("Refresh place details job completed successfully.")
+}
+
+// runExportCoverage writes the stored search circles of a category as GeoJSON and
+// logs how much of each stored area they cover
+func runExportCoverage(ctx context.Context, args []string) {
+	flags := flag.NewFlagSet("run-export-coverage", flag.ExitOnError)
+	category := flags.String("category", "", "category whose search circles to export")
+	areaName := flags.String("area", "", "only measure this area and export the circles reaching into it")
+	output := flags.String("output", "coverage.geojson", "GeoJSON file to write")
+	flags.Parse(args)
+
+	if *category == "" {
+		log.Fatal("Usage: export_coverage --category <category> [--area <name>] [--output <file.geojson>]")
+	}
+
+	client, err := mongodb.NewMongoAdapter()
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB client: %v", err)
+	}
+	defer client.Disconnect(context.TODO())
+
+	coverageService := service.NewGetCoverageService(mongodb.NewPlacesRepo(client), mongodb.NewAreasRepo(client))
+	report, err := coverageService.GetCoverage(ctx, *category, *areaName)
+	if err != nil {
+		log.Fatalf("Failed to measure coverage: %v", err)
+	}
+	if report == nil {
+		log.Fatalf("Area %s not found, fetch it with run-fetch-areas first", *areaName)
+	}
+
+	for _, a := range report.Areas {
+		log.Printf("%s: %.1f%% covered, %.1f%% only in saturated circles, %.1f%% not crawled (%d circles)",
+			a.Name, a.CoveredPercent, a.SaturatedPercent, a.UncoveredPercent, a.Circles)
+	}
+	if err := geojson.WriteFile(*output, geojson.CoverageCollection(*report)); err != nil {
+		log.Fatalf("Failed to write coverage map: %v", err)
+	}
+	log.Printf("Wrote %d search circles and %d areas for %s to %s", len(report.Circles), len(report.Areas), *category, *output)
+}
+
+// parseAge parses a Go duration, also accepting whole days such as 30d
+func parseAge(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days := strings.TrimSuffix(value, "d")
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number of days %q", days)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// planFetchPlaces logs the planned crawl of each category and its estimated cost,
+// and writes the planned circles to planOutput if set
+func planFetchPlaces(ctx context.Context, service *fetch.FetchPlacesService, minLat, maxLat, minLng, maxLng float64, boundary domain.Boundary, grid domain.GridOptions, fieldMask domain.FieldMask, categories []string, planOutput string) {
+	var total domain.CrawlPlan
+	var features []geojson.Feature
+	for _, c := range categories {
+		plan, err := service.PlanFetchPlaces(ctx, minLat, maxLat, minLng, maxLng, boundary, grid, fieldMask, c)
+		if err != nil {
+			log.Fatalf("Failed to plan crawl for %s: %v", c, err)
+		}
+		best, worst := plan.EstimatedCost(fieldMask.NearbySearchSKU)
+		log.Printf("Plan for %s: %d circles (%d already scanned), %d-%.0f requests, estimated $%.2f-$%.2f",
+			c, len(plan.Circles), plan.ScannedCircles, plan.BestCaseRequests, plan.WorstCaseRequests, best, worst)
+
+		total.BestCaseRequests += plan.BestCaseRequests
+		total.WorstCaseRequests += plan.WorstCaseRequests
+		for _, circle := range plan.Circles {
+			features = append(features, geojson.CircleFeature(circle.Circle, map[string]interface{}{
+				"category": c,
+				"radius":   circle.Radius,
+				"scanned":  circle.Scanned,
+			}))
+		}
+	}
+
+	best, worst := total.EstimatedCost(fieldMask.NearbySearchSKU)
+	log.Printf("Dry run: %d-%.0f requests, estimated $%.2f-$%.2f at list price. Nothing was sent to the Places API.",
+		total.BestCaseRequests, total.WorstCaseRequests, best, worst)
+
+	if planOutput != "" {
+		numCircles := len(features)
+		if boundary != nil {
+			features = append(features, geojson.BoundaryFeature(boundary, map[string]interface{}{"boundary": true}))
+		}
+		if err := geojson.WriteFile(planOutput, geojson.NewFeatureCollection(features)); err != nil {
+			log.Fatalf("Failed to write plan: %v", err)
+		}
+		log.Printf("Wrote %d planned circles to %s", numCircles, planOutput)
+	}
+}
+
+// logCrawlReport logs how a crawl ended and how to resume it if it didn't finish
+func logCrawlReport(report *domain.CrawlReport, err error) {
+	if err != nil {
+		if report != nil {
+			log.Printf("Resume with: run-fetch-places --resume %s", report.JobID)
+		}
+		if report != nil && report.Interrupted {
+			return // main exits with the reason
+		}
+		log.Fatalf("Failed to fetch places: %v", err)
+	}
+
+	if report.PendingCircles > 0 {
+		log.Printf("Fetch places job stopped early after covering %d/%d cells (%.1f%%), %d circles left. Resume with: run-fetch-places --resume %s",
+			report.CompletedCells, report.TotalCells, report.CoveragePercent(), report.PendingCircles, report.JobID)
+		return
+	}
+	log.Printf("Fetch places job %s for %s completed successfully.", report.JobID, report.Category)
+}
+
+// loadArea looks up an area saved by run-fetch-areas, suggesting close names if there is none
+func loadArea(ctx context.Context, client *mongo.Client, name string) *domain.Area {
+	areasRepo := mongodb.NewAreasRepo(client)
+	area, err := areasRepo.GetAreaByName(ctx, name)
+	if err != nil {
+		log.Fatalf("Failed to look up area: %v", err)
+	}
+	if area == nil {
+		logAreaSuggestions(ctx, areasRepo, name)
+		log.Fatalf("Area '%s' not found, fetch it first with run-fetch-areas", name)
+	}
+	log.Printf("Using area %s", area.Name)
+	return area
+}
+
+func logAreaSuggestions(ctx context.Context, areasRepo *mongodb.AreasRepo, name string) {
+	areas, err := areasRepo.SearchAreas(ctx, name)
+	if err != nil || len(areas) == 0 {
+		areas, err = areasRepo.ListAreas(ctx)
+	}
+	if err != nil {
+		log.Printf("Failed to list areas: %v", err)
+		return
+	}
+	for _, area := range areas {
+		log.Printf("Saved area: %s (query '%s')", area.Name, area.Query)
+	}
+}
+
+// parseBoundingBox parses <minLat> <maxLat> <minLng> <maxLng>
+func parseBoundingBox(args []string) (minLat, maxLat, minLng, maxLng float64) {
+	minLat, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		log.Fatalf("Invalid minLat: %v", err)
+	}
+
+	maxLat, err = strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		log.Fatalf("Invalid maxLat: %v", err)
+	}
+
+	minLng, err = strconv.ParseFloat(args[2], 64)
+	if err != nil {
+		log.Fatalf("Invalid minLng: %v", err)
+	}
+
+	maxLng, err = strconv.ParseFloat(args[3], 64)
+	if err != nil {
+		log.Fatalf("Invalid maxLng: %v", err)
+	}
+	return minLat, maxLat, minLng, maxLng
+}
+
+// isFlagSet reports whether the flag was passed on the command line
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// addGridFlags registers the flags shaping a crawl's circles
+func addGridFlags(flags *flag.FlagSet) *domain.GridOptions {
+	grid := domain.DefaultGridOptions()
+	flags.Float64Var(&grid.Overlap, "overlap", grid.Overlap, "margin added to each circle's radius so neighbouring circles overlap, e.g. 0.1 for 10%")
+	flags.Float64Var(&grid.MinRadius, "min-radius", grid.MinRadius, "radius in meters below which dense circles are no longer subdivided")
+	return &grid
+}
+
+// addRateLimitFlags registers the Places API rate limit flags, defaulting to the
+// GOOGLE_MAX_QPS, GOOGLE_BURST and GOOGLE_MAX_CONCURRENT_REQUESTS variables
+func addRateLimitFlags(flags *flag.FlagSet) *domain.RateLimits {
+	limits := rateLimitsFromEnv()
+	flags.Float64Var(&limits.QPS, "qps", limits.QPS, "maximum sustained Places API requests per second, 0 for no limit")
+	flags.IntVar(&limits.Burst, "burst", limits.Burst, "Places API requests that may be sent at once after an idle spell")
+	flags.IntVar(&limits.MaxConcurrent, "max-concurrent", limits.MaxConcurrent, "maximum Places API requests in flight, 0 for no limit")
+	return &limits
+}
+
+func rateLimitsFromEnv() domain.RateLimits {
+	limits, err := ratelimit.LimitsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid rate limit configuration: %v", err)
+	}
+	return limits
+}
+
+// setRateLimit makes every Places API adapter share one limiter
+func setRateLimit(limits domain.RateLimits) {
+	api.SetRateLimiter(ratelimit.NewLimiter(limits))
+	log.Printf("Places API rate limit: %.1f requests/s, burst %d, %d in flight", limits.QPS, limits.Burst, limits.MaxConcurrent)
+}
+
+// newBudget loads the Places API budget configured through the GOOGLE_MAX_* variables
+func newBudget(ctx context.Context, client *mongo.Client) *budget.Budget {
+	limits, err := budget.LimitsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid budget configuration: %v", err)
+	}
+	usageRepo := mongodb.NewAPIUsageRepo(client)
+	if err := usageRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to initialize API budget: %v", err)
+	}
+	apiBudget, err := budget.NewBudget(ctx, usageRepo, limits)
+	if err != nil {
+		log.Fatalf("Failed to initialize API budget: %v", err)
+	}
+	return apiBudget
+}
+
+func logBudgetUsage(apiBudget *budget.Budget) {
+	usage := apiBudget.RunUsage()
+	log.Printf("Places API usage for this run: %d requests, estimated cost $%.2f %v", usage.Requests, usage.Cost, usage.SKUs)
+	for _, key := range api.KeyUsage() {
+		status := ""
+		if key.Disabled {
+			status = ", disabled after an auth failure"
+		}
+		log.Printf("API key %s: %d requests, %d quota errors%s", key.Key, key.Requests, key.QuotaErrors, status)
+	}
 }
\ No newline at end of file
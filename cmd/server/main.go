@@ -1,45 +1,70 @@
-package main
-
-import (
-	"log"
-	"os"
-
-	"wheretoeat/internal/adapter/handler/get"
-	"wheretoeat/internal/adapter/repository/postgres"
-	"wheretoeat/internal/adapter/util"
-	"wheretoeat/internal/adapter/service"
-
-	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
-)
-
-func main() {
-	util.LoadEnv()
-
-	// PostgreSQL connection
-	pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
-	if err != nil {
-		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
-	}
-	defer pgDB.Close()
-
-	// Repositories
-	placesRepo := postgres.NewPlacesRepo(pgDB)
-
-	// Services
-	getPlacesService := service.NewGetPlacesService(placesRepo)
-
-	// Handlers
-	getPlacesHandler := get.NewGetPlacesHandler(getPlacesService)
-
-	// Router
-	r := gin.Default()
-	r.GET("/nearby-places", getPlacesHandler.Handle)
-
-
-	// Start server
-	if err := r.Run(":8081"); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-	}
-}
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"wheretoeat/internal/adapter/analysis"
+	"wheretoeat/internal/adapter/handler/get"
+	"wheretoeat/internal/adapter/repository/mongodb"
+	"wheretoeat/internal/adapter/repository/postgres"
+	"wheretoeat/internal/adapter/util"
+	"wheretoeat/internal/adapter/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+func main() {
+	util.LoadEnv()
+
+	// PostgreSQL connection
+	pgDB, err := sqlx.Connect("postgres", os.Getenv("POSTGRES_URI"))
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer pgDB.Close()
+
+	// MongoDB connection, for the search results behind the coverage map
+	mongoClient, err := mongodb.NewMongoAdapter()
+	if err != nil {
+		log.Fatalf("Failed to initialize MongoDB client: %v", err)
+	}
+	defer mongoClient.Disconnect(context.TODO())
+
+	// Repositories
+	placesRepo := postgres.NewPlacesRepo(pgDB)
+	reviewsRepo := postgres.NewReviewsRepo(pgDB)
+	searchResultsRepo := mongodb.NewPlacesRepo(mongoClient)
+	areasRepo := mongodb.NewAreasRepo(mongoClient)
+
+	// Services
+	getPlacesService := service.NewGetPlacesService(placesRepo, analysis.NewDishExtractor())
+	getPlaceService := service.NewGetPlaceService(placesRepo, reviewsRepo)
+	getReviewsService := service.NewGetReviewsService(placesRepo, reviewsRepo)
+	getSimilarPlacesService := service.NewGetSimilarPlacesService(placesRepo)
+	getCoverageService := service.NewGetCoverageService(searchResultsRepo, areasRepo)
+
+	// Handlers
+	getPlacesHandler := get.NewGetPlacesHandler(getPlacesService)
+	getPlaceHandler := get.NewGetPlaceHandler(getPlaceService)
+	getReviewsHandler := get.NewGetReviewsHandler(getReviewsService)
+	getSimilarPlacesHandler := get.NewGetSimilarPlacesHandler(getSimilarPlacesService)
+	getCoverageHandler := get.NewGetCoverageHandler(getCoverageService)
+
+	// Router
+	r := gin.Default()
+	r.GET("/nearby-places", getPlacesHandler.Handle)
+	r.GET("/places/:id", getPlaceHandler.Handle)
+	r.GET("/places/:id/reviews", getReviewsHandler.Handle)
+	r.GET("/places/:id/similar", getSimilarPlacesHandler.Handle)
+	r.GET("/coverage", getCoverageHandler.Handle)
+
+
+	// Start server
+	if err := r.Run(":8081"); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package analysis
+
+import (
+	"strings"
+)
+
+// maxDishLen is the longest dish name, in words, the extractor looks for
+const maxDishLen = 4
+
+// dishEntry is a canonical dish name with the other ways people write it
+type dishEntry struct {
+	Name    string
+	Aliases []string
+}
+
+// dishDictionary lists the dishes recognized in place names, reviews and search strings.
+// Multi-word names and aliases also match when typed without diacritics.
+var dishDictionary = []dishEntry{
+	// Noodles
+	{Name: "phở", Aliases: []string{"pho", "phở bò", "phở gà", "phở cuốn"}},
+	{Name: "bún bò", Aliases: []string{"bún bò huế", "bun bo hue"}},
+	{Name: "bún chả", Aliases: []string{"bún chả hà nội"}},
+	{Name: "bún riêu", Aliases: []string{"bún riêu cua"}},
+	{Name: "bún thịt nướng"},
+	{Name: "bún đậu", Aliases: []string{"bún đậu mắm tôm"}},
+	{Name: "bún mắm"},
+	{Name: "bún mọc"},
+	{Name: "bún cá"},
+	{Name: "bún ốc"},
+	{Name: "hủ tiếu", Aliases: []string{"hủ tíu", "hủ tiếu nam vang", "hu tiu"}},
+	{Name: "mì quảng"},
+	{Name: "mì vịt tiềm"},
+	{Name: "mì cay"},
+	{Name: "bánh canh", Aliases: []string{"bánh canh cua"}},
+	{Name: "cao lầu"},
+	{Name: "miến gà", Aliases: []string{"miến"}},
+	{Name: "ramen"},
+	{Name: "pasta", Aliases: []string{"spaghetti"}},
+
+	// Rice
+	{Name: "cơm tấm", Aliases: []string{"cơm sườn"}},
+	{Name: "cơm gà", Aliases: []string{"cơm gà xối mỡ"}},
+	{Name: "cơm chiên", Aliases: []string{"fried rice"}},
+	{Name: "cơm niêu"},
+	{Name: "cơm văn phòng", Aliases: []string{"cơm bình dân"}},
+	{Name: "xôi"},
+	{Name: "cháo", Aliases: []string{"cháo lòng", "cháo gà"}},
+	{Name: "sushi"},
+
+	// Bánh
+	{Name: "bánh mì", Aliases: []string{"banh mi"}},
+	{Name: "bánh xèo"},
+	{Name: "bánh cuốn"},
+	{Name: "bánh bèo"},
+	{Name: "bánh khọt"},
+	{Name: "bánh căn"},
+	{Name: "bánh tráng trộn", Aliases: []string{"bánh tráng nướng"}},
+	{Name: "bánh bột chiên"},
+	{Name: "bánh flan", Aliases: []string{"flan"}},
+
+	// Rolls, grill and hot pot
+	{Name: "gỏi cuốn", Aliases: []string{"spring rolls", "spring roll"}},
+	{Name: "chả giò", Aliases: []string{"nem rán"}},
+	{Name: "bò bía"},
+	{Name: "bò kho"},
+	{Name: "bò né"},
+	{Name: "bò lá lốt"},
+	{Name: "lẩu", Aliases: []string{"hot pot", "hotpot", "lẩu thái", "lẩu dê", "lẩu bò"}},
+	{Name: "nướng", Aliases: []string{"bbq", "barbecue", "đồ nướng"}},
+	{Name: "ốc", Aliases: []string{"quán ốc"}},
+	{Name: "hải sản", Aliases: []string{"seafood"}},
+	{Name: "vịt quay"},
+	{Name: "gà rán", Aliases: []string{"fried chicken"}},
+	{Name: "dim sum", Aliases: []string{"dimsum"}},
+	{Name: "pizza"},
+	{Name: "burger", Aliases: []string{"hamburger", "burgers"}},
+	{Name: "steak", Aliases: []string{"bít tết", "beefsteak"}},
+
+	// Sweets and drinks
+	{Name: "chè"},
+	{Name: "kem", Aliases: []string{"ice cream"}},
+	{Name: "bánh kem", Aliases: []string{"cake"}},
+	{Name: "cà phê", Aliases: []string{"cafe", "coffee", "cà phê sữa đá", "cà phê muối"}},
+	{Name: "trà sữa", Aliases: []string{"milk tea", "bubble tea"}},
+	{Name: "sinh tố", Aliases: []string{"smoothie"}},
+	{Name: "nước mía"},
+}
+
+// dishIndex maps every lowercase dish name and alias to its canonical name
+func dishIndex() map[string]string {
+	index := make(map[string]string)
+	add := func(phrase, name string) {
+		index[phrase] = name
+		// Typing without diacritics is common, but folding single words
+		// would be ambiguous (lẩu vs lâu), so only multi-word phrases get it.
+		if strings.Contains(phrase, " ") {
+			if folded := foldDiacritics(phrase); folded != phrase {
+				if _, exists := index[folded]; !exists {
+					index[folded] = name
+				}
+			}
+		}
+	}
+	for _, dish := range dishDictionary {
+		add(dish.Name, dish.Name)
+		for _, alias := range dish.Aliases {
+			add(alias, dish.Name)
+		}
+	}
+	return index
+}
+
+var diacriticsReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "ả", "a", "ã", "a", "ạ", "a",
+	"ă", "a", "ắ", "a", "ằ", "a", "ẳ", "a", "ẵ", "a", "ặ", "a",
+	"â", "a", "ấ", "a", "ầ", "a", "ẩ", "a", "ẫ", "a", "ậ", "a",
+	"é", "e", "è", "e", "ẻ", "e", "ẽ", "e", "ẹ", "e",
+	"ê", "e", "ế", "e", "ề", "e", "ể", "e", "ễ", "e", "ệ", "e",
+	"í", "i", "ì", "i", "ỉ", "i", "ĩ", "i", "ị", "i",
+	"ó", "o", "ò", "o", "ỏ", "o", "õ", "o", "ọ", "o",
+	"ô", "o", "ố", "o", "ồ", "o", "ổ", "o", "ỗ", "o", "ộ", "o",
+	"ơ", "o", "ớ", "o", "ờ", "o", "ở", "o", "ỡ", "o", "ợ", "o",
+	"ú", "u", "ù", "u", "ủ", "u", "ũ", "u", "ụ", "u",
+	"ư", "u", "ứ", "u", "ừ", "u", "ử", "u", "ữ", "u", "ự", "u",
+	"ý", "y", "ỳ", "y", "ỷ", "y", "ỹ", "y", "ỵ", "y",
+	"đ", "d",
+)
+
+// foldDiacritics strips Vietnamese diacritics from lowercase text
+func foldDiacritics(text string) string {
+	return diacriticsReplacer.Replace(text)
+}
@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"context"
+	"log"
+	"sort"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+const placesPageSize = 1000 // Places read per page while extracting dishes
+
+type DishExtractionService struct {
+	placesRepo  port.PlacesRepository
+	reviewsRepo port.ReviewsRepository
+	dishesRepo  port.DishesRepository
+	extractor   *DishExtractor
+}
+
+func NewDishExtractionService(placesRepo port.PlacesRepository, reviewsRepo port.ReviewsRepository, dishesRepo port.DishesRepository) *DishExtractionService {
+	return &DishExtractionService{
+		placesRepo:  placesRepo,
+		reviewsRepo: reviewsRepo,
+		dishesRepo:  dishesRepo,
+		extractor:   NewDishExtractor(),
+	}
+}
+
+// ExtractDishes rebuilds the dish mentions of every place from its name and reviews
+func (s *DishExtractionService) ExtractDishes(ctx context.Context) error {
+	for offset := 0; ; offset += placesPageSize {
+		places, err := s.placesRepo.ListPlaces(ctx, placesPageSize, offset)
+		if err != nil {
+			return err
+		}
+		if len(places) == 0 {
+			return nil
+		}
+
+		for _, place := range places {
+			dishes, err := s.extractPlaceDishes(ctx, place)
+			if err != nil {
+				log.Printf("Failed to extract dishes for place %s: %v", place.ID, err)
+				continue
+			}
+			if err := s.dishesRepo.SavePlaceDishes(ctx, place.ID, dishes); err != nil {
+				log.Printf("Failed to save dishes for place %s: %v", place.ID, err)
+				continue
+			}
+		}
+		log.Printf("Extracted dishes for %d places", offset+len(places))
+	}
+}
+
+func (s *DishExtractionService) extractPlaceDishes(ctx context.Context, place domain.Place) ([]domain.PlaceDish, error) {
+	reviews, err := s.reviewsRepo.GetReviews(ctx, place.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	dishes := make(map[string]*domain.PlaceDish)
+	for dish := range s.extractor.ExtractDishes(place.Name) {
+		dishes[dish] = &domain.PlaceDish{PlaceID: place.ID, Dish: dish, InName: true}
+	}
+	for _, review := range reviews {
+		for dish, count := range s.extractor.ExtractDishes(review.Content) {
+			if dishes[dish] == nil {
+				dishes[dish] = &domain.PlaceDish{PlaceID: place.ID, Dish: dish}
+			}
+			dishes[dish].MentionCount += count
+		}
+	}
+
+	result := make([]domain.PlaceDish, 0, len(dishes))
+	for _, d := range dishes {
+		result = append(result, *d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Dish < result[j].Dish })
+	return result, nil
+}
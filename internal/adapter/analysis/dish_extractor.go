@@ -0,0 +1,22 @@
+package analysis
+
+// DishExtractor finds dishes from the dish dictionary in free text
+type DishExtractor struct {
+	index map[string]string // lowercase name or alias -> canonical dish name
+}
+
+func NewDishExtractor() *DishExtractor {
+	return &DishExtractor{index: dishIndex()}
+}
+
+// ExtractDishes counts the dishes mentioned in text, keyed by canonical name
+func (e *DishExtractor) ExtractDishes(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, sentence := range splitSentences(text) {
+		tokens := tokenize(sentence)
+		for _, m := range longestMatches(tokens, maxDishLen, func(p string) bool { _, ok := e.index[p]; return ok }) {
+			counts[e.index[m.phrase]]++
+		}
+	}
+	return counts
+}
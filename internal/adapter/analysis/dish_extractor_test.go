@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestExtractDishes(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want map[string]int
+	}{
+		{"canonical name", "Phở ở đây rất ngon", map[string]int{"phở": 1}},
+		{"uppercase", "BÁNH MÌ NGON", map[string]int{"bánh mì": 1}},
+		{"decomposed diacritics", norm.NFD.String("Bún bò Huế rất ngon"), map[string]int{"bún bò": 1}},
+		{"decomposed uppercase", norm.NFD.String("CƠM TẤM SƯỜN"), map[string]int{"cơm tấm": 1}},
+		{"multi-word name without diacritics", "banh xeo gion", map[string]int{"bánh xèo": 1}},
+		{"single word is not folded", "doi lau qua", map[string]int{}},
+		{"alias", "Best fried rice in town", map[string]int{"cơm chiên": 1}},
+		{"English alias", "great coffee and spring rolls", map[string]int{"cà phê": 1, "gỏi cuốn": 1}},
+		{"longest match wins", "bún bò huế", map[string]int{"bún bò": 1}},
+		{"longer dish is not also the shorter one", "bánh kem dâu", map[string]int{"bánh kem": 1}},
+		{"counts every mention", "Phở ngon. Phở bò cũng ngon!", map[string]int{"phở": 2}},
+		{"punctuation breaks a name", "bún, bò", map[string]int{}},
+		{"no dishes", "Nice place, friendly staff", map[string]int{}},
+	}
+
+	extractor := NewDishExtractor()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractor.ExtractDishes(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDishIndex(t *testing.T) {
+	index := dishIndex()
+
+	tests := []struct {
+		phrase string
+		want   string // Empty when the phrase must not be indexed
+	}{
+		{"phở", "phở"},
+		{"pho", "phở"},
+		{"phở bò", "phở"},
+		{"pho bo", "phở"},
+		{"bánh mì", "bánh mì"},
+		{"banh mi", "bánh mì"},
+		{"mi quang", "mì quảng"},
+		{"milk tea", "trà sữa"},
+		{"lẩu", "lẩu"},
+		{"lau", ""},
+		{"che", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			if got := index[tt.phrase]; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// Review text is NFC-normalized and lowercased before matching, so entries
+// written any other way would never match
+func TestDishDictionaryIsNormalized(t *testing.T) {
+	for _, dish := range dishDictionary {
+		for _, phrase := range append([]string{dish.Name}, dish.Aliases...) {
+			if phrase != strings.ToLower(norm.NFC.String(phrase)) {
+				t.Errorf("%q is not lowercase NFC", phrase)
+			}
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,108 @@
+package analysis
+
+import (
+	"wheretoeat/internal/core/domain"
+)
+
+// Lexicon entries are lowercase phrases of up to maxPhraseLen words, matched
+// against the tokenized review text. Vietnamese words are kept with their
+// diacritics since that's how Google returns review text.
+const maxPhraseLen = 3
+
+// aspectKeywords maps each aspect to the words that signal a clause is about it
+var aspectKeywords = map[string][]string{
+	domain.AspectFood: {
+		"food", "dish", "dishes", "taste", "flavor", "flavour", "meal", "portion", "portions",
+		"noodle", "noodles", "soup", "broth", "meat", "drink", "drinks", "coffee", "menu",
+		"món", "món ăn", "đồ ăn", "thức ăn", "vị", "hương vị", "nước dùng", "nước lèo",
+		"thịt", "đồ uống", "nước uống", "cà phê", "phần ăn", "phần",
+		// Sentiment words that only ever describe food, so "Quán ngon" counts
+		"delicious", "tasty", "yummy", "bland", "salty", "stale",
+		"ngon", "vừa miệng", "thơm", "nhạt", "mặn", "nguội",
+	},
+	domain.AspectService: {
+		"service", "staff", "waiter", "waitress", "waiters", "server", "owner", "employees",
+		"phục vụ", "nhân viên", "chủ quán", "thái độ", "anh chủ", "chị chủ", "cô chủ",
+		"friendly", "unfriendly", "rude", "polite", "attentive", "helpful",
+		"thân thiện", "nhiệt tình", "chu đáo", "lịch sự", "thô lỗ", "cau có", "cọc",
+	},
+	domain.AspectPrice: {
+		"price", "prices", "priced", "cost", "value", "money", "cheap", "expensive",
+		"affordable", "overpriced", "pricey",
+		"giá", "giá cả", "tiền", "đắt", "rẻ", "mắc", "chát", "bình dân",
+	},
+	domain.AspectCleanliness: {
+		"clean", "dirty", "hygiene", "hygienic", "toilet", "restroom", "bathroom", "flies",
+		"sạch", "sạch sẽ", "dơ", "bẩn", "vệ sinh", "nhà vệ sinh", "ruồi", "gián",
+	},
+	domain.AspectWaitTime: {
+		"wait", "waiting", "waited", "queue", "line", "minutes", "slow", "quick", "fast",
+		"chờ", "đợi", "xếp hàng", "phút", "lâu", "nhanh", "chậm",
+	},
+}
+
+var positiveWords = []string{
+	"good", "great", "delicious", "tasty", "excellent", "amazing", "friendly", "nice",
+	"fresh", "clean", "cheap", "affordable", "reasonable", "fast", "quick", "love",
+	"best", "recommend", "perfect", "polite", "attentive", "worth", "helpful", "yummy",
+	"ngon", "tuyệt", "tuyệt vời", "tốt", "thân thiện", "nhiệt tình", "dễ thương",
+	"sạch", "sạch sẽ", "rẻ", "hợp lý", "nhanh", "tươi", "đáng", "ổn", "chu đáo",
+	"lịch sự", "vừa miệng", "xuất sắc", "thơm", "bình dân",
+}
+
+var negativeWords = []string{
+	"bad", "terrible", "awful", "bland", "salty", "rude", "slow", "dirty",
+	"expensive", "overpriced", "pricey", "worst", "disappointing", "disappointed",
+	"stale", "horrible", "poor", "long", "unfriendly", "noisy", "smelly",
+	"dở", "tệ", "chán", "nhạt", "mặn", "nguội", "thô lỗ", "chậm", "lâu", "dơ", "bẩn",
+	"đắt", "mắc", "chát", "thất vọng", "khó chịu", "cau có", "hôi", "ồn", "cọc",
+}
+
+// negators flip the polarity of a sentiment word that follows within negationWindow words
+var negators = []string{
+	"not", "no", "never", "hardly", "don", "didn", "doesn", "isn", "wasn", "aren", "weren",
+	"không", "chẳng", "chả", "chưa", "ko", "k", "hông", "hổng",
+}
+
+const negationWindow = 2
+
+// clauseBreakers split a sentence into clauses so that sentiment in
+// "food is great but service is slow" is attributed to the right aspect.
+// "mà" is left out: it joins as often as it contrasts ("ngon mà rẻ").
+var clauseBreakers = []string{"but", "however", "although", "though", "nhưng", "tuy"}
+
+// stopwords are skipped when collecting frequent phrases
+var stopwords = []string{
+	"the", "a", "an", "and", "or", "is", "are", "was", "were", "be", "been", "it", "its",
+	"this", "that", "to", "of", "in", "on", "for", "with", "at", "i", "we", "you", "they",
+	"my", "our", "very", "so", "too", "here", "there", "also", "have", "has", "had", "but",
+	"là", "và", "thì", "có", "của", "cho", "với", "ở", "này", "đó", "rất", "quá", "lắm",
+	"cũng", "nên", "được", "mình", "tôi", "em", "anh", "chị", "mà", "nhưng", "đi", "ăn",
+	"một", "những", "các", "nhiều", "hơn", "đã", "sẽ", "thấy", "khá",
+}
+
+// highlightLabels are the at-a-glance labels for a clearly positive or negative aspect
+var highlightLabels = map[string][2]string{ // aspect -> {positive, negative}
+	domain.AspectFood:        {"tasty food", "disappointing food"},
+	domain.AspectService:     {"friendly staff", "unfriendly staff"},
+	domain.AspectPrice:       {"cheap", "pricey"},
+	domain.AspectCleanliness: {"clean", "dirty"},
+	domain.AspectWaitTime:    {"quick service", "slow service"},
+}
+
+// aspectOrder keeps summaries and highlights in a stable order
+var aspectOrder = []string{
+	domain.AspectFood,
+	domain.AspectService,
+	domain.AspectPrice,
+	domain.AspectCleanliness,
+	domain.AspectWaitTime,
+}
+
+func toSet(words []string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
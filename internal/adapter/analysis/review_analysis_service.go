@@ -0,0 +1,46 @@
+package analysis
+
+import (
+	"context"
+	"log"
+
+	"wheretoeat/internal/core/port"
+)
+
+type ReviewAnalysisService struct {
+	reviewsRepo port.ReviewsRepository
+	analyzer    *ReviewAnalyzer
+}
+
+func NewReviewAnalysisService(reviewsRepo port.ReviewsRepository) *ReviewAnalysisService {
+	return &ReviewAnalysisService{
+		reviewsRepo: reviewsRepo,
+		analyzer:    NewReviewAnalyzer(),
+	}
+}
+
+// AnalyzeReviews rebuilds the review summary of every place that has reviews
+func (s *ReviewAnalysisService) AnalyzeReviews(ctx context.Context) error {
+	placeIDs, err := s.reviewsRepo.GetReviewedPlaceIDs(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Analyzing reviews of %d places", len(placeIDs))
+
+	for _, placeID := range placeIDs {
+		reviews, err := s.reviewsRepo.GetReviews(ctx, placeID)
+		if err != nil {
+			log.Printf("Failed to get reviews for place %s: %v", placeID, err)
+			continue
+		}
+
+		summary := s.analyzer.Analyze(placeID, reviews)
+		if err := s.reviewsRepo.SaveReviewSummary(ctx, summary); err != nil {
+			log.Printf("Failed to save review summary for place %s: %v", placeID, err)
+			continue
+		}
+		log.Printf("Analyzed %d reviews for place %s: %v", summary.ReviewCount, placeID, summary.Highlights)
+	}
+
+	return nil
+}
@@ -0,0 +1,274 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+	"wheretoeat/internal/core/domain"
+)
+
+const (
+	maxTopPhrases  = 10  // Number of frequent phrases kept per place
+	minPhraseCount = 2   // A phrase must appear in at least this many reviews
+	minOpinions    = 2   // Opinionated clauses needed before an aspect gets a highlight
+	highlightScore = 0.3 // Minimum |score| for an aspect to get a highlight
+)
+
+// ReviewAnalyzer runs lexicon-based aspect sentiment over review text.
+// It needs no external services and handles Vietnamese and English.
+type ReviewAnalyzer struct {
+	aspectIndex map[string][]string // phrase -> aspects it signals
+	dishes      map[string]string   // Dish names and aliases, mentions of which are about food
+	positive    map[string]bool
+	negative    map[string]bool
+	negators    map[string]bool
+	breakers    map[string]bool
+	stopwords   map[string]bool
+}
+
+func NewReviewAnalyzer() *ReviewAnalyzer {
+	aspectIndex := make(map[string][]string)
+	for aspect, keywords := range aspectKeywords {
+		for _, k := range keywords {
+			aspectIndex[k] = append(aspectIndex[k], aspect)
+		}
+	}
+	return &ReviewAnalyzer{
+		aspectIndex: aspectIndex,
+		dishes:      dishIndex(),
+		positive:    toSet(positiveWords),
+		negative:    toSet(negativeWords),
+		negators:    toSet(negators),
+		breakers:    toSet(clauseBreakers),
+		stopwords:   toSet(stopwords),
+	}
+}
+
+// Analyze builds the aspect summary, frequent phrases and highlights for one place
+func (a *ReviewAnalyzer) Analyze(placeID string, reviews []domain.Review) domain.ReviewSummary {
+	aspects := make(map[string]*domain.AspectSentiment)
+	for _, aspect := range aspectOrder {
+		aspects[aspect] = &domain.AspectSentiment{Aspect: aspect}
+	}
+	phraseCounts := make(map[string]int)
+
+	for _, review := range reviews {
+		seenPhrases := make(map[string]bool) // Count each phrase once per review
+		for _, clause := range a.clauses(review.Content) {
+			a.scoreClause(clause, aspects)
+			for _, phrase := range a.phrases(clause) {
+				seenPhrases[phrase] = true
+			}
+		}
+		for phrase := range seenPhrases {
+			phraseCounts[phrase]++
+		}
+	}
+
+	summary := domain.ReviewSummary{
+		PlaceID:     placeID,
+		ReviewCount: len(reviews),
+		TopPhrases:  topPhrases(phraseCounts),
+	}
+	for _, aspect := range aspectOrder {
+		s := aspects[aspect]
+		if s.Positive+s.Negative > 0 {
+			s.Score = float64(s.Positive-s.Negative) / float64(s.Positive+s.Negative)
+		}
+		summary.Aspects = append(summary.Aspects, *s)
+
+		if s.Positive+s.Negative >= minOpinions && math.Abs(s.Score) >= highlightScore {
+			labels := highlightLabels[aspect]
+			if s.Score > 0 {
+				summary.Highlights = append(summary.Highlights, labels[0])
+			} else {
+				summary.Highlights = append(summary.Highlights, labels[1])
+			}
+		}
+	}
+	return summary
+}
+
+// scoreClause attributes the clause's overall polarity to every aspect it mentions.
+// Naming a dish ("Phở ngon") is a mention of food.
+func (a *ReviewAnalyzer) scoreClause(tokens []string, aspects map[string]*domain.AspectSentiment) {
+	mentioned := make(map[string]bool)
+	for _, m := range longestMatches(tokens, maxPhraseLen, func(p string) bool { return len(a.aspectIndex[p]) > 0 }) {
+		for _, aspect := range a.aspectIndex[m.phrase] {
+			mentioned[aspect] = true
+		}
+	}
+	if len(longestMatches(tokens, maxDishLen, func(p string) bool { _, ok := a.dishes[p]; return ok })) > 0 {
+		mentioned[domain.AspectFood] = true
+	}
+	if len(mentioned) == 0 {
+		return
+	}
+
+	polarity := 0
+	for _, m := range longestMatches(tokens, maxPhraseLen, func(p string) bool { return a.positive[p] || a.negative[p] }) {
+		sign := 1
+		if a.negative[m.phrase] {
+			sign = -1
+		}
+		if a.isNegated(tokens, m.start) {
+			sign = -sign
+		}
+		polarity += sign
+	}
+
+	for aspect := range mentioned {
+		s := aspects[aspect]
+		s.Mentions++
+		switch {
+		case polarity > 0:
+			s.Positive++
+		case polarity < 0:
+			s.Negative++
+		}
+	}
+}
+
+// isNegated reports whether a negator appears shortly before the word at start
+func (a *ReviewAnalyzer) isNegated(tokens []string, start int) bool {
+	for i := start - 1; i >= 0 && i >= start-negationWindow; i-- {
+		if a.negators[tokens[i]] {
+			return true
+		}
+	}
+	return false
+}
+
+// clauses lowercases and tokenizes text, splitting on punctuation and contrastive conjunctions
+func (a *ReviewAnalyzer) clauses(text string) [][]string {
+	var clauses [][]string
+	for _, sentence := range splitSentences(text) {
+		var clause []string
+		for _, token := range tokenize(sentence) {
+			if a.breakers[token] {
+				if len(clause) > 0 {
+					clauses = append(clauses, clause)
+				}
+				clause = nil
+				continue
+			}
+			clause = append(clause, token)
+		}
+		if len(clause) > 0 {
+			clauses = append(clauses, clause)
+		}
+	}
+	return clauses
+}
+
+// phrases returns the 2- and 3-word phrases of a clause that neither start nor end with a stopword
+func (a *ReviewAnalyzer) phrases(tokens []string) []string {
+	var phrases []string
+	for n := 2; n <= maxPhraseLen; n++ {
+		for i := 0; i+n <= len(tokens); i++ {
+			first, last := tokens[i], tokens[i+n-1]
+			if a.stopwords[first] || a.stopwords[last] || a.negators[first] || isNumber(first) || isNumber(last) {
+				continue
+			}
+			phrases = append(phrases, strings.Join(tokens[i:i+n], " "))
+		}
+	}
+	return phrases
+}
+
+type match struct {
+	phrase string
+	start  int
+}
+
+// longestMatches scans tokens left to right, preferring the longest phrase
+// of at most maxLen words accepted by ok
+func longestMatches(tokens []string, maxLen int, ok func(string) bool) []match {
+	var matches []match
+	for i := 0; i < len(tokens); {
+		matched := 0
+		for n := maxLen; n >= 1; n-- {
+			if i+n > len(tokens) {
+				continue
+			}
+			phrase := strings.Join(tokens[i:i+n], " ")
+			if ok(phrase) {
+				matches = append(matches, match{phrase: phrase, start: i})
+				matched = n
+				break
+			}
+		}
+		if matched == 0 {
+			matched = 1
+		}
+		i += matched
+	}
+	return matches
+}
+
+// topPhrases picks the most frequent phrases, dropping a phrase when a longer
+// one containing it is just as frequent
+func topPhrases(counts map[string]int) []domain.PhraseCount {
+	var candidates []domain.PhraseCount
+	for phrase, count := range counts {
+		if count >= minPhraseCount {
+			candidates = append(candidates, domain.PhraseCount{Phrase: phrase, Count: count})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Count != candidates[j].Count {
+			return candidates[i].Count > candidates[j].Count
+		}
+		if len(candidates[i].Phrase) != len(candidates[j].Phrase) {
+			return len(candidates[i].Phrase) > len(candidates[j].Phrase)
+		}
+		return candidates[i].Phrase < candidates[j].Phrase
+	})
+
+	var top []domain.PhraseCount
+	for _, c := range candidates {
+		redundant := false
+		for _, kept := range top {
+			if kept.Count == c.Count && strings.Contains(" "+kept.Phrase+" ", " "+c.Phrase+" ") {
+				redundant = true
+				break
+			}
+		}
+		if redundant {
+			continue
+		}
+		top = append(top, c)
+		if len(top) == maxTopPhrases {
+			break
+		}
+	}
+	return top
+}
+
+// splitSentences normalizes and lowercases text and splits it on punctuation.
+// Vietnamese text may arrive with decomposed diacritics (NFD, common from macOS
+// and some keyboards), which would never match the precomposed lexicon.
+func splitSentences(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(norm.NFC.String(text)), func(r rune) bool {
+		return strings.ContainsRune(".,!?;:\n()…", r)
+	})
+}
+
+// tokenize splits text into NFC-normalized words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(norm.NFC.String(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r)
+	})
+}
+
+func isNumber(token string) bool {
+	for _, r := range token {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
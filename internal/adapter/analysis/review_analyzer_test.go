@@ -0,0 +1,52 @@
+package analysis
+
+import (
+	"reflect"
+	"testing"
+
+	"wheretoeat/internal/core/domain"
+)
+
+func TestAnalyzeAspects(t *testing.T) {
+	tests := []struct {
+		review string
+		want   map[string]float64 // Score of every mentioned aspect
+	}{
+		// English
+		{"Delicious!", map[string]float64{domain.AspectFood: 1}},
+		{"Cold drinks were great", map[string]float64{domain.AspectFood: 1}},
+		{"Not tasty at all", map[string]float64{domain.AspectFood: -1}},
+		{"Great pizza and cheap", map[string]float64{domain.AspectFood: 1, domain.AspectPrice: 1}},
+		{"The food is great but the staff were rude", map[string]float64{domain.AspectFood: 1, domain.AspectService: -1}},
+		{"Friendly and attentive", map[string]float64{domain.AspectService: 1}},
+		{"Waited 40 minutes, too slow", map[string]float64{domain.AspectWaitTime: -1}},
+		{"Nice place", map[string]float64{}},
+
+		// Vietnamese
+		{"Quán ngon", map[string]float64{domain.AspectFood: 1}},
+		{"Phở ngon…", map[string]float64{domain.AspectFood: 1}},
+		{"Bún chả ở đây dở", map[string]float64{domain.AspectFood: -1}},
+		{"ngon mà rẻ", map[string]float64{domain.AspectFood: 1, domain.AspectPrice: 1}},
+		{"Đồ ăn không ngon", map[string]float64{domain.AspectFood: -1}},
+		{"Giá hơi đắt", map[string]float64{domain.AspectPrice: -1}},
+		{"Đồ ăn ngon nhưng phục vụ chậm", map[string]float64{domain.AspectFood: 1, domain.AspectService: -1, domain.AspectWaitTime: -1}},
+		{"Nhân viên thân thiện, quán sạch sẽ", map[string]float64{domain.AspectService: 1, domain.AspectCleanliness: 1}},
+		{"Quán ở gần nhà", map[string]float64{}},
+	}
+
+	analyzer := NewReviewAnalyzer()
+	for _, tt := range tests {
+		t.Run(tt.review, func(t *testing.T) {
+			summary := analyzer.Analyze("place", []domain.Review{{Content: tt.review}})
+			got := make(map[string]float64)
+			for _, s := range summary.Aspects {
+				if s.Mentions > 0 {
+					got[s.Aspect] = s.Score
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// APIError is a non-200 response from the Places API, decoded from Google's error body:
+// {"error": {"code": 403, "message": "...", "status": "PERMISSION_DENIED"}}
+type APIError struct {
+	StatusCode int
+	Status     string        // Google status, e.g. RESOURCE_EXHAUSTED
+	Message    string
+	RetryAfter time.Duration // From the Retry-After header, 0 if absent
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("places API error %d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+// Unwrap maps the error onto the domain error classes
+func (e *APIError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return domain.ErrPlacesAPIAuth
+	case e.StatusCode == http.StatusTooManyRequests:
+		return domain.ErrPlacesAPIQuota
+	case e.StatusCode >= 500:
+		return domain.ErrPlacesAPIUnavailable
+	default:
+		return domain.ErrPlacesAPIInvalidRequest
+	}
+}
+
+// Retryable reports whether the same request may succeed later
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// transportError is a request that got no response at all, e.g. a refused or reset
+// connection or an attempt timeout. Unlike a malformed request, it may succeed later.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("error making API request: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+func parseAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Status: http.StatusText(resp.StatusCode)}
+
+	var errorBody struct {
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errorBody); err == nil && errorBody.Error.Message != "" {
+		apiErr.Message = errorBody.Error.Message
+		if errorBody.Error.Status != "" {
+			apiErr.Status = errorBody.Error.Status
+		}
+	} else {
+		apiErr.Message = string(body)
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	"net/url"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// PlaceDetailsAPI implements PlaceDetailsAPIPort for Place Details
+type PlaceDetailsAPI struct {
+	fieldMask domain.FieldMask
+}
+
+func NewPlaceDetailsAPI(fieldMask domain.FieldMask) *PlaceDetailsAPI {
+	return &PlaceDetailsAPI{fieldMask: fieldMask}
+}
+
+func (a *PlaceDetailsAPI) FieldMask() domain.FieldMask {
+	return a.fieldMask
+}
+
+func (a *PlaceDetailsAPI) FetchPlaceDetails(ctx context.Context, placeID string) (*domain.Place, error) {
+	endpoint := "https://places.googleapis.com/v1/places/" + url.PathEscape(placeID)
+
+	result, err := getPlace(ctx, endpoint, a.fieldMask.Header(""))
+	if err != nil {
+		return nil, err
+	}
+	return &result.Place, nil
+}
\ No newline at end of file
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"wheretoeat/internal/adapter/apikey"
+	"wheretoeat/internal/adapter/ratelimit"
+	"wheretoeat/internal/core/domain"
+)
+
+const (
+	maxAttempts    = 5                      // Attempts per request, including the first one
+	baseBackoff    = 500 * time.Millisecond // Backoff before the first retry
+	maxBackoff     = 30 * time.Second       // Upper bound of a single backoff
+	attemptTimeout = 30 * time.Second       // Bounds a single attempt, which is then retried
+)
+
+// limiter paces every request of every adapter, retries included. Unlimited until SetRateLimiter is called.
+var limiter = ratelimit.NewLimiter(domain.RateLimits{})
+
+// keys holds the API keys every adapter sends requests with. It is read with
+// apikey.KeysFromEnv on first use, once the job has loaded its .env file.
+var (
+	keys     *apikey.Pool
+	keysOnce sync.Once
+)
+
+// attemptHookKey is the context key of the hook set by WithAttemptHook
+type attemptHookKey struct{}
+
+// WithAttemptHook returns a context whose requests call hook before every attempt they
+// send, retries included, and fail with its error. It runs once the attempt has a key
+// and a rate limiter slot, right before sending. Budgets use it to pay for each attempt.
+func WithAttemptHook(ctx context.Context, hook func(context.Context) error) context.Context {
+	return context.WithValue(ctx, attemptHookKey{}, hook)
+}
+
+func keyPool() *apikey.Pool {
+	keysOnce.Do(func() {
+		keys = apikey.NewPool(apikey.KeysFromEnv())
+	})
+	return keys
+}
+
+// SetRateLimiter makes every adapter share the given limiter. Call it before sending any request.
+func SetRateLimiter(l *ratelimit.Limiter) {
+	limiter = l
+}
+
+// KeyUsage returns how much each API key was used so far
+func KeyUsage() []apikey.Usage {
+	return keyPool().Usage()
+}
+
+// searchPlaces POSTs a search request and returns the places of the response
+func searchPlaces(ctx context.Context, url string, requestBody interface{}, fieldMask string) ([]domain.PlaceResult, error) {
+	places, _, err := searchPlacesPage(ctx, url, requestBody, fieldMask)
+	return places, err
+}
+
+// searchPlacesPage is searchPlaces for paginated searches, also returning the
+// token of the next page, empty on the last page
+func searchPlacesPage(ctx context.Context, url string, requestBody interface{}, fieldMask string) ([]domain.PlaceResult, string, error) {
+	body, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, "", fmt.Errorf("error encoding API request: %w", err)
+	}
+
+	respBody, err := sendWithRetries(ctx, "POST", url, body, fieldMask)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// A response without places has no "places" at all
+	var result searchResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, "", fmt.Errorf("error decoding API response: %w", err)
+	}
+
+	// A place that doesn't decode is skipped, the rest of the page is still good
+	places := make([]domain.PlaceResult, 0, len(result.Places))
+	for _, raw := range result.Places {
+		place, err := decodePlace(raw)
+		if err != nil {
+			log.Printf("Skipping place of %s: %v", url, err)
+			continue
+		}
+		places = append(places, place)
+	}
+	return places, result.NextPageToken, nil
+}
+
+// getPlace GETs a single place resource
+func getPlace(ctx context.Context, url string, fieldMask string) (domain.PlaceResult, error) {
+	respBody, err := sendWithRetries(ctx, "GET", url, nil, fieldMask)
+	if err != nil {
+		return domain.PlaceResult{}, err
+	}
+	return decodePlace(respBody)
+}
+
+// sendWithRetries sends a request and returns the response body. Quota (429) and
+// server (5xx) errors are retried with exponential backoff and jitter; auth and
+// request errors fail immediately. A key that hits its quota or is rejected is
+// swapped for another one of the pool, which takes the retry without a backoff.
+func sendWithRetries(ctx context.Context, method, url string, body []byte, fieldMask string) ([]byte, error) {
+	var lastErr error
+	switched := false
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 && !switched {
+			delay := backoff(attempt-1, lastErr)
+			log.Printf("Retrying %s in %v (attempt %d/%d): %v", url, delay, attempt, maxAttempts, lastErr)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		key, err := keyPool().Acquire()
+		if err != nil {
+			return nil, err
+		}
+		release, err := limiter.Acquire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if hook, ok := ctx.Value(attemptHookKey{}).(func(context.Context) error); ok {
+			if err := hook(ctx); err != nil {
+				release()
+				return nil, err
+			}
+		}
+		respBody, err := send(ctx, method, url, body, fieldMask, key.Value)
+		release()
+		if err == nil {
+			return respBody, nil
+		}
+
+		switched = false
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			switch {
+			case errors.Is(err, domain.ErrPlacesAPIQuota):
+				switched = keyPool().QuotaExceeded(key, apiErr.RetryAfter)
+			case errors.Is(err, domain.ErrPlacesAPIAuth):
+				switched = keyPool().Rejected(key)
+			}
+		}
+		if !switched && !isRetryable(ctx, err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("giving up after %d attempts: %w", maxAttempts, lastErr)
+}
+
+func send(ctx context.Context, method, url string, body []byte, fieldMask, apiKey string) ([]byte, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("error creating API request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Goog-Api-Key", apiKey)
+	req.Header.Set("X-Goog-FieldMask", fieldMask)
+
+	client := &http.Client{Timeout: attemptTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading API response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseAPIError(resp, respBody)
+	}
+	return respBody, nil
+}
+
+// isRetryable reports whether err is worth another attempt: quota and server errors,
+// and transport errors and timeouts unless the context itself is done. A request that
+// can't be built or a response that can't be read would fail the same way again.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	var transportErr *transportError
+	return errors.As(err, &transportErr)
+}
+
+// backoff returns the delay before retry n (1-based) using full jitter,
+// but never less than what the server asked for with Retry-After
+func backoff(n int, lastErr error) time.Duration {
+	ceiling := baseBackoff << (n - 1)
+	if ceiling > maxBackoff || ceiling <= 0 {
+		ceiling = maxBackoff
+	}
+	delay := time.Duration(rand.Int63n(int64(ceiling))) + baseBackoff/2
+
+	var apiErr *APIError
+	if errors.As(lastErr, &apiErr) && apiErr.RetryAfter > delay {
+		delay = apiErr.RetryAfter
+	}
+	return delay
+}
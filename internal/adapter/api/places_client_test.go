@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"wheretoeat/internal/adapter/ratelimit"
+	"wheretoeat/internal/core/domain"
+)
+
+func TestSendWithRetriesCallsHookPerAttempt(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEYS", "test-key")
+
+	tests := []struct {
+		name         string
+		statuses     []int // Returned by the server in order, then 200
+		budget       int   // Attempts the hook allows before failing
+		wantAttempts int32
+		wantHooks    int32
+		wantErr      error
+	}{
+		{"first attempt succeeds", nil, 10, 1, 1, nil},
+		{"retried server errors", []int{http.StatusServiceUnavailable, http.StatusInternalServerError}, 10, 3, 3, nil},
+		{"budget runs out on a retry", []int{http.StatusServiceUnavailable}, 1, 1, 2, domain.ErrBudgetExhausted},
+		{"request error is not retried", []int{http.StatusBadRequest}, 10, 1, 1, domain.ErrPlacesAPIInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if int(n) <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[n-1])
+					return
+				}
+				w.Write([]byte(`{"places": []}`))
+			}))
+			defer server.Close()
+
+			var hooks int32
+			ctx := WithAttemptHook(context.Background(), func(ctx context.Context) error {
+				if int(atomic.AddInt32(&hooks, 1)) > tt.budget {
+					return fmt.Errorf("no requests left: %w", domain.ErrBudgetExhausted)
+				}
+				return nil
+			})
+
+			_, err := sendWithRetries(ctx, http.MethodPost, server.URL, []byte(`{}`), "places.id")
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("server got %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+			if hooks != tt.wantHooks {
+				t.Errorf("hook ran %d times, want %d", hooks, tt.wantHooks)
+			}
+		})
+	}
+}
+
+func TestSendWithRetriesSkipsHookWhileRateLimited(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEYS", "test-key")
+
+	// Hold the only slot so the request waits at the limiter until it times out
+	SetRateLimiter(ratelimit.NewLimiter(domain.RateLimits{MaxConcurrent: 1}))
+	defer SetRateLimiter(ratelimit.NewLimiter(domain.RateLimits{}))
+	release, err := limiter.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	var hooks int32
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	ctx = WithAttemptHook(ctx, func(ctx context.Context) error {
+		atomic.AddInt32(&hooks, 1)
+		return nil
+	})
+
+	if _, err := sendWithRetries(ctx, http.MethodGet, "http://127.0.0.1:0", nil, "places.id"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if hooks != 0 {
+		t.Errorf("hook ran %d times for a request never sent", hooks)
+	}
+}
+
+func TestSearchPlacesPageSkipsUndecodablePlaces(t *testing.T) {
+	t.Setenv("GOOGLE_API_KEYS", "test-key")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"places": [{"id": "a"}, {"id": "b", "rating": "4.5"}, {"id": "c"}], "nextPageToken": "next"}`))
+	}))
+	defer server.Close()
+
+	places, token, err := searchPlacesPage(context.Background(), server.URL, map[string]string{}, "places.id")
+	if err != nil {
+		t.Fatalf("searchPlacesPage: %v", err)
+	}
+	var ids []string
+	for _, p := range places {
+		ids = append(ids, p.Place.ID)
+	}
+	if fmt.Sprint(ids) != "[a c]" || token != "next" {
+		t.Errorf("got places %v and token %q, want [a c] and %q", ids, token, "next")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	truncated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(`{"places"`))
+	}))
+	defer truncated.Close()
+
+	sendErr := func(method, url string) error {
+		_, err := send(context.Background(), method, url, nil, "places.id", "test-key")
+		return err
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"connection refused", context.Background(), sendErr(http.MethodGet, closed.URL), true},
+		{"attempt timeout", context.Background(), &transportError{err: os.ErrDeadlineExceeded}, true},
+		{"transport error after the context is done", cancelled, sendErr(http.MethodGet, closed.URL), false},
+		{"invalid URL", context.Background(), sendErr(http.MethodGet, "://places"), false},
+		{"invalid method", context.Background(), sendErr("BAD METHOD", truncated.URL), false},
+		{"truncated response", context.Background(), sendErr(http.MethodGet, truncated.URL), false},
+		{"server error", context.Background(), &APIError{StatusCode: http.StatusServiceUnavailable}, true},
+		{"quota error", context.Background(), &APIError{StatusCode: http.StatusTooManyRequests}, true},
+		{"invalid request", context.Background(), &APIError{StatusCode: http.StatusBadRequest}, false},
+		{"wrapped server error", context.Background(), fmt.Errorf("giving up: %w", &APIError{StatusCode: http.StatusBadGateway}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.err == nil {
+				t.Fatal("test setup got no error")
+			}
+			if got := isRetryable(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
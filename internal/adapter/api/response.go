@@ -0,0 +1,36 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// searchResponse is the body of a Nearby Search or Text Search response. Places stay
+// raw until decodePlace, so each is kept as sent alongside its decoded form.
+type searchResponse struct {
+	Places        []json.RawMessage `json:"places"`
+	NextPageToken string            `json:"nextPageToken"` // Empty on the last page
+}
+
+// decodePlace decodes one place of a response into a domain.Place. Place only carries
+// BSON tags, which follow the API's field names, so the JSON goes through BSON the same
+// way the ETL decodes stored search results and both always agree on the shape.
+func decodePlace(raw json.RawMessage) (domain.PlaceResult, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return domain.PlaceResult{}, fmt.Errorf("error decoding place: %w", err)
+	}
+	data, err := bson.Marshal(fields)
+	if err != nil {
+		return domain.PlaceResult{}, fmt.Errorf("error converting place: %w", err)
+	}
+	var place domain.Place
+	if err := bson.Unmarshal(data, &place); err != nil {
+		return domain.PlaceResult{}, fmt.Errorf("error decoding place %v: %w", fields["id"], err)
+	}
+	return domain.PlaceResult{Place: place, Raw: raw}, nil
+}
\ No newline at end of file
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"wheretoeat/internal/core/domain"
+)
+
+func TestDecodePlace(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    domain.Place
+		wantErr bool
+	}{
+		{
+			name: "numeric fields",
+			raw:  `{"id": "a", "rating": 4.5, "userRatingCount": 1234, "utcOffsetMinutes": 420, "location": {"latitude": 10.7769, "longitude": 106.7009}}`,
+			want: domain.Place{ID: "a", Rating: 4.5, UserRatingCount: 1234, UTCOffsetMinutes: 420, Location: &domain.Coordinates{Lat: 10.7769, Lng: 106.7009}},
+		},
+		{
+			name: "text and list fields",
+			raw:  `{"id": "a", "displayName": {"text": "Phở Hòa"}, "types": ["restaurant", "food"], "priceLevel": "PRICE_LEVEL_INEXPENSIVE", "dineIn": true}`,
+			want: domain.Place{ID: "a", DisplayName: &domain.DisplayName{Text: "Phở Hòa"}, Types: []string{"restaurant", "food"}, PriceLevel: "PRICE_LEVEL_INEXPENSIVE", DineIn: true},
+		},
+		{
+			name: "missing fields",
+			raw:  `{"id": "a"}`,
+			want: domain.Place{ID: "a"},
+		},
+		{
+			name: "unknown fields",
+			raw:  `{"id": "a", "somethingNew": {"nested": [1, 2]}}`,
+			want: domain.Place{ID: "a"},
+		},
+		{name: "rating as a string", raw: `{"id": "a", "rating": "4.5"}`, wantErr: true},
+		{name: "fractional rating count", raw: `{"id": "a", "userRatingCount": 12.5}`, wantErr: true},
+		{name: "types not a list", raw: `{"id": "a", "types": "restaurant"}`, wantErr: true},
+		{name: "location not an object", raw: `{"id": "a", "location": [10.7769, 106.7009]}`, wantErr: true},
+		{name: "not an object", raw: `["a"]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodePlace(json.RawMessage(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(got.Place, tt.want) {
+				t.Errorf("got %+v, want %+v", got.Place, tt.want)
+			}
+			if string(got.Raw) != tt.raw {
+				t.Errorf("got raw %s, want it kept as sent", got.Raw)
+			}
+		})
+	}
+}
\ No newline at end of file
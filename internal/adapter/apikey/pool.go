@@ -0,0 +1,167 @@
+package apikey
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// defaultCooldown is how long a key rests after a quota error without Retry-After
+const defaultCooldown = time.Minute
+
+// Pool hands out Google API keys. Requests stay on one key until it runs into its
+// quota, then move on to the next one; keys Google rejects are disabled for the
+// rest of the run. It is safe for concurrent use.
+type Pool struct {
+	mu      sync.Mutex
+	keys    []*poolKey
+	current int
+}
+
+type poolKey struct {
+	value     string
+	usage     Usage
+	coolUntil time.Time // Not used before then, after a quota error
+}
+
+// Key is a key handed out by Acquire, to report how its request ended
+type Key struct {
+	Value string
+	index int
+}
+
+// Usage counts what was sent with one key during the run
+type Usage struct {
+	Key         string // Masked, only the last characters are shown
+	Requests    int    // Attempts, retries included
+	QuotaErrors int
+	Disabled    bool // Rejected by Google and no longer used
+}
+
+// NewPool builds a pool of the given keys, in order, ignoring empty and repeated ones
+func NewPool(keys []string) *Pool {
+	p := &Pool{}
+	seen := make(map[string]bool)
+	for _, value := range keys {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		p.keys = append(p.keys, &poolKey{value: value, usage: Usage{Key: mask(value)}})
+	}
+	return p
+}
+
+// KeysFromEnv reads the comma separated keys of GOOGLE_API_KEYS, falling back to GOOGLE_API_KEY
+func KeysFromEnv() []string {
+	if value := os.Getenv("GOOGLE_API_KEYS"); value != "" {
+		return strings.Split(value, ",")
+	}
+	return []string{os.Getenv("GOOGLE_API_KEY")}
+}
+
+// Acquire returns the key to send the next request with. When every key is over its
+// quota it returns the one that rests the shortest, and the caller's backoff paces the retries.
+func (p *Pool) Acquire() (Key, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.keys) == 0 {
+		return Key{}, fmt.Errorf("no Google API key configured, set GOOGLE_API_KEY or GOOGLE_API_KEYS: %w", domain.ErrPlacesAPIAuth)
+	}
+
+	now := time.Now()
+	soonest := -1
+	for i := range p.keys {
+		index := (p.current + i) % len(p.keys)
+		k := p.keys[index]
+		if k.usage.Disabled {
+			continue
+		}
+		if !k.coolUntil.After(now) {
+			return p.use(index), nil
+		}
+		if soonest < 0 || k.coolUntil.Before(p.keys[soonest].coolUntil) {
+			soonest = index
+		}
+	}
+	if soonest < 0 {
+		return Key{}, fmt.Errorf("every Google API key was rejected: %w", domain.ErrPlacesAPIAuth)
+	}
+	return p.use(soonest), nil
+}
+
+func (p *Pool) use(index int) Key {
+	p.current = index
+	p.keys[index].usage.Requests++
+	return Key{Value: p.keys[index].value, index: index}
+}
+
+// QuotaExceeded rests the key for retryAfter, or a minute if Google didn't say, and
+// reports whether another key can take the retry straight away
+func (p *Pool) QuotaExceeded(key Key, retryAfter time.Duration) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if retryAfter <= 0 {
+		retryAfter = defaultCooldown
+	}
+	k := p.keys[key.index]
+	k.usage.QuotaErrors++
+	now := time.Now()
+	if !k.coolUntil.After(now) && len(p.keys) > 1 {
+		log.Printf("API key %s is over its quota, resting it for %v", k.usage.Key, retryAfter)
+	}
+	k.coolUntil = now.Add(retryAfter)
+	return p.available(now)
+}
+
+// Rejected disables the key after an auth failure and reports whether any key is left
+func (p *Pool) Rejected(key Key) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	k := p.keys[key.index]
+	if !k.usage.Disabled {
+		k.usage.Disabled = true
+		log.Printf("API key %s was rejected, disabling it", k.usage.Key)
+	}
+	for _, k := range p.keys {
+		if !k.usage.Disabled {
+			return true
+		}
+	}
+	return false
+}
+
+// available reports whether an enabled key is not resting
+func (p *Pool) available(now time.Time) bool {
+	for _, k := range p.keys {
+		if !k.usage.Disabled && !k.coolUntil.After(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// Usage returns the usage of every key, in the order they were configured
+func (p *Pool) Usage() []Usage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	usage := make([]Usage, 0, len(p.keys))
+	for _, k := range p.keys {
+		usage = append(usage, k.usage)
+	}
+	return usage
+}
+
+// mask keeps the last four characters of a key, enough to tell keys apart in logs
+func mask(value string) string {
+	if len(value) <= 4 {
+		return "..."
+	}
+	return "..." + value[len(value)-4:]
+}
\ No newline at end of file
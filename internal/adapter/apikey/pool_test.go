@@ -0,0 +1,146 @@
+package apikey
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// step acts on the pool. quota and reject report on the key acquired last.
+type step struct {
+	action     string        // acquire, quota, reject or wait
+	duration   time.Duration // Retry-After for quota, how long to wait for wait
+	wantKey    string        // Key acquire hands out, empty when it must fail
+	wantSwitch bool          // What quota and reject report
+}
+
+func TestPool(t *testing.T) {
+	tests := []struct {
+		name  string
+		keys  []string
+		steps []step
+	}{
+		{"stays on one key", []string{"a", "b"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "acquire", wantKey: "a"},
+			{action: "acquire", wantKey: "a"},
+		}},
+		{"quota moves on to the next key", []string{"a", "b"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", duration: time.Minute, wantSwitch: true},
+			{action: "acquire", wantKey: "b"},
+			{action: "acquire", wantKey: "b"},
+		}},
+		{"rotation wraps around", []string{"a", "b", "c"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", duration: 30 * time.Millisecond, wantSwitch: true},
+			{action: "acquire", wantKey: "b"},
+			{action: "quota", duration: time.Minute, wantSwitch: true},
+			{action: "acquire", wantKey: "c"},
+			{action: "wait", duration: 50 * time.Millisecond},
+			{action: "quota", duration: time.Minute, wantSwitch: true},
+			{action: "acquire", wantKey: "a"},
+		}},
+		{"every key over its quota", []string{"a", "b"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", duration: time.Minute, wantSwitch: true},
+			{action: "acquire", wantKey: "b"},
+			{action: "quota", duration: 2 * time.Minute, wantSwitch: false},
+			{action: "acquire", wantKey: "a"}, // Rests the shortest
+		}},
+		{"quota without Retry-After rests a minute", []string{"a", "b"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", wantSwitch: true},
+			{action: "acquire", wantKey: "b"},
+			{action: "quota", duration: 2 * time.Minute, wantSwitch: false},
+			{action: "acquire", wantKey: "a"},
+		}},
+		{"cooldown ends", []string{"a"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", duration: 20 * time.Millisecond, wantSwitch: false},
+			{action: "wait", duration: 30 * time.Millisecond},
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", duration: time.Minute, wantSwitch: false},
+		}},
+		{"rejected keys are disabled", []string{"a", "b"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "reject", wantSwitch: true},
+			{action: "acquire", wantKey: "b"},
+			{action: "acquire", wantKey: "b"},
+			{action: "reject", wantSwitch: false},
+			{action: "acquire"},
+		}},
+		{"a resting key is still available after a rejection", []string{"a", "b"}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", duration: time.Minute, wantSwitch: true},
+			{action: "acquire", wantKey: "b"},
+			{action: "reject", wantSwitch: true},
+			{action: "acquire", wantKey: "a"},
+		}},
+		{"repeated and empty keys are ignored", []string{"a", " ", "a", ""}, []step{
+			{action: "acquire", wantKey: "a"},
+			{action: "quota", duration: time.Minute, wantSwitch: false},
+		}},
+		{"no keys", []string{""}, []step{
+			{action: "acquire"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPool(tt.keys)
+			var key Key
+			for i, s := range tt.steps {
+				switch s.action {
+				case "acquire":
+					k, err := p.Acquire()
+					if s.wantKey == "" {
+						if !errors.Is(err, domain.ErrPlacesAPIAuth) {
+							t.Fatalf("step %d: got key %q and error %v, want %v", i, k.Value, err, domain.ErrPlacesAPIAuth)
+						}
+						continue
+					}
+					if err != nil || k.Value != s.wantKey {
+						t.Fatalf("step %d: got key %q and error %v, want key %q", i, k.Value, err, s.wantKey)
+					}
+					key = k
+				case "quota":
+					if got := p.QuotaExceeded(key, s.duration); got != s.wantSwitch {
+						t.Fatalf("step %d: QuotaExceeded reported %v, want %v", i, got, s.wantSwitch)
+					}
+				case "reject":
+					if got := p.Rejected(key); got != s.wantSwitch {
+						t.Fatalf("step %d: Rejected reported %v, want %v", i, got, s.wantSwitch)
+					}
+				case "wait":
+					time.Sleep(s.duration)
+				}
+			}
+		})
+	}
+}
+
+func TestPoolUsage(t *testing.T) {
+	p := NewPool([]string{"first-key-1234", "second-key-5678"})
+	a, _ := p.Acquire()
+	p.Acquire()
+	p.QuotaExceeded(a, time.Minute)
+	b, _ := p.Acquire()
+	p.Rejected(b)
+
+	want := []Usage{
+		{Key: "...1234", Requests: 2, QuotaErrors: 1},
+		{Key: "...5678", Requests: 1, Disabled: true},
+	}
+	got := p.Usage()
+	if len(got) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("key %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
\ No newline at end of file
@@ -0,0 +1,156 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// Budget enforces per-run and per-day caps on Places API requests and
+// estimated cost. Daily usage is persisted so the caps hold across runs, and
+// checked by the repository as it is counted so concurrent runs can't overshoot.
+type Budget struct {
+	usageRepo port.APIUsageRepository
+	limits    domain.BudgetLimits
+
+	mu       sync.Mutex
+	runUsage domain.APIUsage
+}
+
+func NewBudget(ctx context.Context, usageRepo port.APIUsageRepository, limits domain.BudgetLimits) (*Budget, error) {
+	day := today()
+	usage, err := usageRepo.GetUsage(ctx, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API usage for %s: %w", day, err)
+	}
+	log.Printf("Places API usage today: %d requests, estimated cost $%.2f", usage.Requests, usage.Cost)
+	return &Budget{
+		usageRepo: usageRepo,
+		limits:    limits,
+		runUsage:  domain.APIUsage{SKUs: map[string]int{}},
+	}, nil
+}
+
+// LimitsFromEnv reads the caps from GOOGLE_MAX_REQUESTS_PER_RUN, GOOGLE_MAX_REQUESTS_PER_DAY,
+// GOOGLE_MAX_COST_PER_RUN and GOOGLE_MAX_COST_PER_DAY. Unset variables mean no cap.
+func LimitsFromEnv() (domain.BudgetLimits, error) {
+	var limits domain.BudgetLimits
+	var err error
+	if limits.MaxRequestsPerRun, err = intFromEnv("GOOGLE_MAX_REQUESTS_PER_RUN"); err != nil {
+		return limits, err
+	}
+	if limits.MaxRequestsPerDay, err = intFromEnv("GOOGLE_MAX_REQUESTS_PER_DAY"); err != nil {
+		return limits, err
+	}
+	if limits.MaxCostPerRun, err = floatFromEnv("GOOGLE_MAX_COST_PER_RUN"); err != nil {
+		return limits, err
+	}
+	if limits.MaxCostPerDay, err = floatFromEnv("GOOGLE_MAX_COST_PER_DAY"); err != nil {
+		return limits, err
+	}
+	return limits, nil
+}
+
+// Reserve accounts for one request of the given SKU, or returns an error
+// wrapping domain.ErrBudgetExhausted if it would go over a cap. It fails
+// closed: a request whose usage can't be recorded is not sent.
+func (b *Budget) Reserve(ctx context.Context, sku domain.SKU) error {
+	cost := domain.SKUCostPerRequest[sku]
+
+	b.mu.Lock()
+	if err := b.checkRunLimits(cost); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	// Taken right away so concurrent requests can't overshoot the run caps either
+	addUsage(&b.runUsage, sku, cost, 1)
+	b.mu.Unlock()
+
+	day := today()
+	reserved, err := b.usageRepo.ReserveUsage(ctx, day, sku, cost, b.limits.MaxRequestsPerDay, b.limits.MaxCostPerDay)
+	if err != nil {
+		err = fmt.Errorf("failed to record API usage for %s: %w", day, err)
+	} else if !reserved {
+		err = fmt.Errorf("%w: daily request or cost cap reached", domain.ErrBudgetExhausted)
+	}
+	if err != nil {
+		b.mu.Lock()
+		addUsage(&b.runUsage, sku, -cost, -1)
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// reserver is a hook for api.WithAttemptHook that reserves one request of the SKU
+func (b *Budget) reserver(sku domain.SKU) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return b.Reserve(ctx, sku)
+	}
+}
+
+// RunUsage returns the usage accounted by this run so far
+func (b *Budget) RunUsage() domain.APIUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	usage := b.runUsage
+	usage.SKUs = make(map[string]int, len(b.runUsage.SKUs))
+	for sku, n := range b.runUsage.SKUs {
+		usage.SKUs[sku] = n
+	}
+	return usage
+}
+
+func (b *Budget) checkRunLimits(cost float64) error {
+	l := b.limits
+	switch {
+	case l.MaxRequestsPerRun > 0 && b.runUsage.Requests+1 > l.MaxRequestsPerRun:
+		return fmt.Errorf("%w: run request cap of %d reached", domain.ErrBudgetExhausted, l.MaxRequestsPerRun)
+	case l.MaxCostPerRun > 0 && b.runUsage.Cost+cost > l.MaxCostPerRun:
+		return fmt.Errorf("%w: run cost cap of $%.2f reached", domain.ErrBudgetExhausted, l.MaxCostPerRun)
+	}
+	return nil
+}
+
+// addUsage adds n requests of sku costing cost in total, n is -1 to take a request back
+func addUsage(usage *domain.APIUsage, sku domain.SKU, cost float64, n int) {
+	usage.Requests += n
+	usage.Cost += cost
+	usage.SKUs[string(sku)] += n
+}
+
+// today is the current budget day. Days are counted in UTC.
+func today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+func intFromEnv(key string) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func floatFromEnv(key string) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,68 @@
+package budget
+
+import (
+	"context"
+
+	"wheretoeat/internal/adapter/api"
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// BudgetedPlacesAPI wraps a PlacesAPIPort and reserves budget before every attempt the
+// api client sends, so retried requests are paid for too
+type BudgetedPlacesAPI struct {
+	api    port.PlacesAPIPort
+	budget *Budget
+	sku    domain.SKU
+}
+
+func NewBudgetedPlacesAPI(api port.PlacesAPIPort, budget *Budget, sku domain.SKU) *BudgetedPlacesAPI {
+	return &BudgetedPlacesAPI{api: api, budget: budget, sku: sku}
+}
+
+func (a *BudgetedPlacesAPI) FetchPlaces(ctx context.Context, params domain.RequestParams) ([]domain.PlaceResult, error) {
+	return a.api.FetchPlaces(api.WithAttemptHook(ctx, a.budget.reserver(a.sku)), params)
+}
+
+func (a *BudgetedPlacesAPI) FieldMask() domain.FieldMask {
+	return a.api.FieldMask()
+}
+
+// BudgetedPlaceDetailsAPI wraps a PlaceDetailsAPIPort and reserves budget before every attempt
+type BudgetedPlaceDetailsAPI struct {
+	api    port.PlaceDetailsAPIPort
+	budget *Budget
+	sku    domain.SKU
+}
+
+func NewBudgetedPlaceDetailsAPI(api port.PlaceDetailsAPIPort, budget *Budget, sku domain.SKU) *BudgetedPlaceDetailsAPI {
+	return &BudgetedPlaceDetailsAPI{api: api, budget: budget, sku: sku}
+}
+
+func (a *BudgetedPlaceDetailsAPI) FetchPlaceDetails(ctx context.Context, placeID string) (*domain.Place, error) {
+	return a.api.FetchPlaceDetails(api.WithAttemptHook(ctx, a.budget.reserver(a.sku)), placeID)
+}
+
+func (a *BudgetedPlaceDetailsAPI) FieldMask() domain.FieldMask {
+	return a.api.FieldMask()
+}
+
+// BudgetedTextSearchAPI wraps a TextSearchAPIPort and reserves budget before every attempt
+// at every page
+type BudgetedTextSearchAPI struct {
+	api    port.TextSearchAPIPort
+	budget *Budget
+	sku    domain.SKU
+}
+
+func NewBudgetedTextSearchAPI(api port.TextSearchAPIPort, budget *Budget, sku domain.SKU) *BudgetedTextSearchAPI {
+	return &BudgetedTextSearchAPI{api: api, budget: budget, sku: sku}
+}
+
+func (a *BudgetedTextSearchAPI) SearchText(ctx context.Context, params domain.RequestParams) ([]domain.PlaceResult, string, error) {
+	return a.api.SearchText(api.WithAttemptHook(ctx, a.budget.reserver(a.sku)), params)
+}
+
+func (a *BudgetedTextSearchAPI) FieldMask() domain.FieldMask {
+	return a.api.FieldMask()
+}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package fake
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// LoadPlaces reads a dataset for FakePlacesAPI. The file is either a JSON array of
+// places, a Places API response ({"places": [...]}), or a recording written by
+// RecordingPlacesAPI, whose places are deduplicated by ID.
+func LoadPlaces(path string) ([]map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading places dataset: %w", err)
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		var places []map[string]interface{}
+		if err := json.Unmarshal(data, &places); err != nil {
+			return nil, fmt.Errorf("error decoding places dataset: %w", err)
+		}
+		return places, nil
+	case strings.HasPrefix(trimmed, `{"places"`):
+		var response struct {
+			Places []map[string]interface{} `json:"places"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("error decoding places dataset: %w", err)
+		}
+		return response.Places, nil
+	default:
+		return loadRecording(trimmed)
+	}
+}
+
+// loadRecording collects the places of every recorded response, keeping the first copy of each
+func loadRecording(data string) ([]map[string]interface{}, error) {
+	var places []map[string]interface{}
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024) // Responses with reviews are large
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var entry recordedCall
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error decoding recording line %d: %w", line, err)
+		}
+		for _, raw := range entry.Places {
+			var place map[string]interface{}
+			if err := json.Unmarshal(raw, &place); err != nil {
+				return nil, fmt.Errorf("error decoding place on recording line %d: %w", line, err)
+			}
+			id, _ := place["id"].(string)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			places = append(places, place)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading recording: %w", err)
+	}
+	return places, nil
+}
+
+// GeneratePlaces builds a reproducible synthetic dataset of count places around
+// the circle's center. Places get denser towards the center, so a crawl over it
+// hits the per-request cap and subdivides there, like a busy district.
+func GeneratePlaces(seed int64, count int, area domain.Circle, primaryTypes []string) []map[string]interface{} {
+	rng := rand.New(rand.NewSource(seed))
+	metersPerDegLat := 1 / domain.LatMeterToDegree
+	metersPerDegLng := 1 / domain.LngMeterToDegree(area.Lat)
+
+	places := make([]map[string]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		// Squaring the uniform draw concentrates places near the center
+		distance := area.Radius * math.Pow(rng.Float64(), 2)
+		bearing := rng.Float64() * 2 * math.Pi
+		lat := area.Lat + distance*math.Cos(bearing)/metersPerDegLat
+		lng := area.Lng + distance*math.Sin(bearing)/metersPerDegLng
+		primaryType := primaryTypes[rng.Intn(len(primaryTypes))]
+
+		places = append(places, map[string]interface{}{
+			"id":          fmt.Sprintf("fake-%d-%d", seed, i),
+			"displayName": map[string]interface{}{"text": fmt.Sprintf("Fake %s %d", strings.ReplaceAll(primaryType, "_", " "), i), "languageCode": "en"},
+			"primaryType": primaryType,
+			"types":       []interface{}{primaryType, "food", "point_of_interest", "establishment"},
+			"location":    map[string]interface{}{"latitude": lat, "longitude": lng},
+			"rating":      math.Round((3+rng.Float64()*2)*10) / 10,
+		})
+	}
+	return places
+}
+
+// WritePlaces saves a dataset as a JSON array that LoadPlaces can read back
+func WritePlaces(path string, places []map[string]interface{}) error {
+	data, err := json.MarshalIndent(places, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding places dataset: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing places dataset: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// FakePlacesAPI implements PlacesAPIPort over an in-memory dataset, so crawls can
+// run offline and deterministically. Like Nearby Search it only returns places
+// inside the request circle, nearest first, capped at MaxResultsPerReq.
+type FakePlacesAPI struct {
+	fieldMask domain.FieldMask
+	places    []map[string]interface{}
+
+	mu       sync.Mutex
+	calls    []domain.RequestParams
+	failures []error                          // Returned by the next calls, in order
+	failWhen func(domain.RequestParams) error // Checked on every call after failures
+}
+
+// NewFakePlacesAPI serves the given places, which use the Places API JSON shape
+// (at least "id" and "location", plus "primaryType" and "displayName" to match requests)
+func NewFakePlacesAPI(fieldMask domain.FieldMask, places []map[string]interface{}) *FakePlacesAPI {
+	return &FakePlacesAPI{fieldMask: fieldMask, places: places}
+}
+
+func (a *FakePlacesAPI) FieldMask() domain.FieldMask {
+	return a.fieldMask
+}
+
+// FailNext makes the next calls return the given errors, one per call
+func (a *FakePlacesAPI) FailNext(errs ...error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failures = append(a.failures, errs...)
+}
+
+// FailWhen makes every call for which fn returns an error fail with it
+func (a *FakePlacesAPI) FailWhen(fn func(domain.RequestParams) error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.failWhen = fn
+}
+
+// Calls returns the parameters of every request made so far, failed ones included
+func (a *FakePlacesAPI) Calls() []domain.RequestParams {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]domain.RequestParams(nil), a.calls...)
+}
+
+func (a *FakePlacesAPI) FetchPlaces(ctx context.Context, params domain.RequestParams) ([]domain.PlaceResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := a.nextFailure(params); err != nil {
+		return nil, err
+	}
+
+	nearby := len(params.Types) > 0
+	if nearby && (params.Circle.Radius <= 0 || params.Circle.Radius > domain.MaxSearchRadius) {
+		return nil, fmt.Errorf("radius %.0f out of range (0, %.0f]: %w", params.Circle.Radius, domain.MaxSearchRadius, domain.ErrPlacesAPIInvalidRequest)
+	}
+	if !nearby && params.Query == "" {
+		return nil, fmt.Errorf("request has neither types nor a query: %w", domain.ErrPlacesAPIInvalidRequest)
+	}
+
+	center := domain.LatLng{Lat: params.Circle.Lat, Lng: params.Circle.Lng}
+	type candidate struct {
+		place    map[string]interface{}
+		distance float64
+	}
+	var candidates []candidate
+	for _, place := range a.places {
+		location, ok := placeLocation(place)
+		if !ok {
+			continue
+		}
+		distance := domain.DistanceMeters(center, location)
+		if nearby {
+			// locationRestriction: only places inside the circle
+			if distance > params.Circle.Radius || !hasAnyType(place, params.Types) {
+				continue
+			}
+		} else if !matchesQuery(place, params.Query) {
+			continue
+		}
+		candidates = append(candidates, candidate{place: place, distance: distance})
+	}
+
+	// Text Search without a circle has no center to rank by, keep the dataset order
+	if nearby || params.Circle.Radius > 0 {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].distance < candidates[j].distance
+		})
+	}
+	if len(candidates) > domain.MaxResultsPerReq {
+		candidates = candidates[:domain.MaxResultsPerReq]
+	}
+
+	places := make([]domain.PlaceResult, 0, len(candidates))
+	for _, c := range candidates {
+		place, err := placeResult(a.mask(c.place))
+		if err != nil {
+			return nil, err
+		}
+		places = append(places, place)
+	}
+	return places, nil
+}
+
+// nextFailure records the call and returns the error it should fail with, if any
+func (a *FakePlacesAPI) nextFailure(params domain.RequestParams) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.calls = append(a.calls, params)
+	if len(a.failures) > 0 {
+		err := a.failures[0]
+		a.failures = a.failures[1:]
+		return err
+	}
+	if a.failWhen != nil {
+		return a.failWhen(params)
+	}
+	return nil
+}
+
+// mask copies the place keeping only the fields of the field mask
+func (a *FakePlacesAPI) mask(place map[string]interface{}) map[string]interface{} {
+	masked := make(map[string]interface{}, len(place))
+	if a.fieldMask.Fields == nil {
+		for k, v := range place {
+			masked[k] = v
+		}
+		return masked
+	}
+	for _, field := range a.fieldMask.Fields {
+		if v, ok := place[field]; ok {
+			masked[field] = v
+		}
+	}
+	return masked
+}
+
+// placeResult decodes a dataset place like the real adapters decode a response,
+// through its JSON and the BSON tags of domain.Place
+func placeResult(place map[string]interface{}) (domain.PlaceResult, error) {
+	raw, err := json.Marshal(place)
+	if err != nil {
+		return domain.PlaceResult{}, fmt.Errorf("error encoding place %v: %w", place["id"], err)
+	}
+	data, err := bson.Marshal(place)
+	if err != nil {
+		return domain.PlaceResult{}, fmt.Errorf("error converting place %v: %w", place["id"], err)
+	}
+	var decoded domain.Place
+	if err := bson.Unmarshal(data, &decoded); err != nil {
+		return domain.PlaceResult{}, fmt.Errorf("error decoding place %v: %w", place["id"], err)
+	}
+	return domain.PlaceResult{Place: decoded, Raw: raw}, nil
+}
+
+func placeLocation(place map[string]interface{}) (domain.LatLng, bool) {
+	location, ok := place["location"].(map[string]interface{})
+	if !ok {
+		return domain.LatLng{}, false
+	}
+	lat, latOk := location["latitude"].(float64)
+	lng, lngOk := location["longitude"].(float64)
+	return domain.LatLng{Lat: lat, Lng: lng}, latOk && lngOk
+}
+
+// hasAnyType mirrors includedPrimaryTypes, which only matches a place's primary type
+func hasAnyType(place map[string]interface{}, types []string) bool {
+	primaryType, _ := place["primaryType"].(string)
+	for _, t := range types {
+		if t == primaryType {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesQuery is a crude stand-in for Text Search: every word of the query
+// must appear in the place's name, address or types
+func matchesQuery(place map[string]interface{}, query string) bool {
+	var text []string
+	if displayName, ok := place["displayName"].(map[string]interface{}); ok {
+		name, _ := displayName["text"].(string)
+		text = append(text, name)
+	}
+	address, _ := place["formattedAddress"].(string)
+	text = append(text, address)
+	if types, ok := place["types"].([]interface{}); ok {
+		for _, t := range types {
+			if s, ok := t.(string); ok {
+				text = append(text, strings.ReplaceAll(s, "_", " "))
+			}
+		}
+	}
+	haystack := strings.ToLower(strings.Join(text, " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(haystack, word) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// recordedCall is one line of a recording
+type recordedCall struct {
+	Params    domain.RequestParams `json:"params"`
+	Places    []json.RawMessage    `json:"places,omitempty"` // As sent, see domain.PlaceResult
+	Error     string               `json:"error,omitempty"`
+	Timestamp time.Time            `json:"timestamp"`
+}
+
+// RecordingPlacesAPI wraps a PlacesAPIPort and appends every request and its
+// response to a JSON lines file, which LoadPlaces can later replay from
+type RecordingPlacesAPI struct {
+	api  port.PlacesAPIPort
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewRecordingPlacesAPI(api port.PlacesAPIPort, path string) (*RecordingPlacesAPI, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording file: %w", err)
+	}
+	return &RecordingPlacesAPI{api: api, file: file}, nil
+}
+
+func (a *RecordingPlacesAPI) FetchPlaces(ctx context.Context, params domain.RequestParams) ([]domain.PlaceResult, error) {
+	places, err := a.api.FetchPlaces(ctx, params)
+
+	entry := recordedCall{Params: params, Timestamp: time.Now()}
+	for _, place := range places {
+		entry.Places = append(entry.Places, place.Raw)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if recordErr := a.record(entry); recordErr != nil && err == nil {
+		return places, recordErr
+	}
+	return places, err
+}
+
+func (a *RecordingPlacesAPI) FieldMask() domain.FieldMask {
+	return a.api.FieldMask()
+}
+
+func (a *RecordingPlacesAPI) Close() error {
+	return a.file.Close()
+}
+
+func (a *RecordingPlacesAPI) record(entry recordedCall) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding recorded call: %w", err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("error writing recording: %w", err)
+	}
+	return nil
+}
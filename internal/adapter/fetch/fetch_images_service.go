@@ -1,209 +1,253 @@
-package fetch
-
-import (
-	"context"
-	"io"
-	"fmt"
-	"log"
-	"math/rand"
-	"net/http"
-	"os"
-	"sync"
-	"time"
-
-	"github.com/gocolly/colly/v2"
-	"wheretoeat/internal/core/domain"
-	"wheretoeat/internal/core/port"
-)
-
-const (
-	numWorkers    = 5  // Number of concurrent workers
-	minDelay      = 100 * time.Millisecond
-	maxDelay      = 1 * time.Second
-)
-
-// Shared Colly collector template (cloned per worker for thread safety)
-var baseCollector = colly.NewCollector(
-	colly.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"),
-	colly.AllowURLRevisit(),
-)
-
-func init() {
-	baseCollector.SetRequestTimeout(30 * time.Second)
-	baseCollector.WithTransport(&http.Transport{
-		MaxIdleConns:        10,
-		IdleConnTimeout:     30 * time.Second,
-		TLSHandshakeTimeout: 10 * time.Second,
-	})
-}
-
-type FetchImagesService struct {
-	photoStorage  port.Storage
-	placesRepo    port.PlacesRepository
-}
-
-func NewFetchImagesService(photoStorage port.Storage, placesRepo port.PlacesRepository) *FetchImagesService {
-	return &FetchImagesService{
-		photoStorage: photoStorage,
-		placesRepo:    placesRepo,
-	}
-}
-
-// FetchImages runs the pipeline to fetch and process images
-func (s *FetchImagesService) FetchImages(ctx context.Context, limit, offset int) error {
-	// Step 1: Producer - Fetch photos from Postgres
-	photos, err := s.placesRepo.GetPhotos(ctx, limit, offset)
-	if err != nil {
-		log.Printf("Failed to fetch photos: %v", err)
-		return err
-	}
-	log.Printf("Fetched %d photos from Postgres", len(photos))
-
-	// Step 2: Pipeline setup
-	photoChan := make(chan domain.Photo, len(photos))
-	resultChan := make(chan error, len(photos))
-	var wg sync.WaitGroup
-
-	// Start workers
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go s.worker(ctx, photoChan, resultChan, &wg)
-	}
-
-	// Feed photos into the pipeline
-	go func() {
-		for _, photo := range photos {
-			photoChan <- photo
-		}
-		close(photoChan)
-	}()
-
-	// Wait for workers to finish and close result channel
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	// Step 3: Consume results
-	for err := range resultChan {
-		if err != nil {
-			return err // Return first error
-		}
-	}
-
-	return nil
-}
-
-// worker processes photos in the pipeline
-func (s *FetchImagesService) worker(ctx context.Context, photoChan <-chan domain.Photo, resultChan chan<- error, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	// Clone the base collector for thread safety
-	collector := baseCollector.Clone()
-
-	for photo := range photoChan {
-		log.Printf("Worker processing photo for place %s, photo %s", photo.PlaceID, photo.PhotoId)
-		
-		if photo.ImageUrl.Valid {
-			log.Printf("Photo already uploaded to storage: %s", photo.ImageUrl.String)
-			resultChan <- nil
-			continue
-		}
-
-		// Fetch image URL with random delay
-		imageURL, err := fetchImageURL(photo.FlagContentUri, collector)
-		if err != nil {
-			log.Printf("Failed to fetch image URL for %s: %v", photo.FlagContentUri, err)
-			resultChan <- err
-			continue
-		}
-
-		// Download image with random delay
-		imagePath, err := downloadImage(imageURL)
-		if err != nil {
-			log.Printf("Failed to download image from %s: %v", imageURL, err)
-			resultChan <- err
-			continue
-		}
-		defer os.Remove(imagePath)
-
-		// Upload image
-		imgURL, err := s.photoStorage.Upload(ctx, imagePath, "images/"+photo.PlaceID+"/"+photo.PhotoId+".jpg")
-		if err != nil {
-			log.Printf("Failed to upload image for %s/%s: %v", photo.PlaceID, photo.PhotoId, err)
-			resultChan <- err
-			continue
-		}
-
-		// Update photo URL in Postgres
-		err = s.placesRepo.UpdatePhotoURL(ctx, imgURL, photo.PlaceID, photo.PhotoId)
-		if err != nil {
-			log.Printf("Failed to update photo URL for %s/%s: %v", photo.PlaceID, photo.PhotoId, err)
-			resultChan <- err
-			continue
-		}
-
-		log.Printf("Successfully processed image for %s/%s, uploaded to %s", photo.PlaceID, photo.PhotoId, imgURL)
-		resultChan <- nil
-	}
-}
-
-// fetchImageURL fetches the image URL from the provided URI with a random delay
-func fetchImageURL(uri string, c *colly.Collector) (string, error) {
-	// Random delay to bypass rate limiting
-	time.Sleep(minDelay + time.Duration(rand.Int63n(int64(maxDelay-minDelay))))
-
-	log.Printf("Fetching image URL for %s", uri)
-	var imageURL string
-
-	c.OnHTML("img#preview-image", func(e *colly.HTMLElement) {
-		imageURL = e.Attr("src")
-		log.Printf("Found image URL: %s", imageURL)
-	})
-
-	c.OnError(func(r *colly.Response, err error) {
-		log.Printf("Error fetching %s: %v", uri, err)
-	})
-
-	err := c.Visit(uri)
-	if err != nil {
-		return "", err
-	}
-
-	if imageURL == "" {
-		return "", fmt.Errorf("no image URL found in %s", uri)
-	}
-
-	return imageURL, nil
-}
-
-// downloadImage downloads the image from the URL with a random delay
-func downloadImage(imageURL string) (string, error) {
-	// Random delay to bypass rate limiting
-	time.Sleep(minDelay + time.Duration(rand.Int63n(int64(maxDelay-minDelay))))
-
-	log.Printf("Downloading image from %s", imageURL)
-	resp, err := http.Get(imageURL)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to download image from %s: status %d", imageURL, resp.StatusCode)
-	}
-
-	tempFile, err := os.CreateTemp("", "image-*.jpg")
-	if err != nil {
-		return "", err
-	}
-	defer tempFile.Close()
-
-	_, err = io.Copy(tempFile, resp.Body)
-	if err != nil {
-		return "", err
-	}
-
-	log.Printf("Downloaded image to %s", tempFile.Name())
-	return tempFile.Name(), nil
+package fetch
+
+import (
+	"context"
+	"io"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gocolly/colly/v2"
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+const (
+	numWorkers      = 5  // Number of concurrent workers
+	minDelay        = 100 * time.Millisecond
+	maxDelay        = 1 * time.Second
+	downloadTimeout = 60 * time.Second // Per image, the scrape itself times out in the collector
+)
+
+// Shared Colly collector template (cloned per worker for thread safety)
+var baseCollector = colly.NewCollector(
+	colly.UserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"),
+	colly.AllowURLRevisit(),
+)
+
+func init() {
+	baseCollector.SetRequestTimeout(30 * time.Second)
+	baseCollector.WithTransport(&http.Transport{
+		MaxIdleConns:        10,
+		IdleConnTimeout:     30 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	})
+}
+
+type FetchImagesService struct {
+	photoStorage  port.Storage
+	placesRepo    port.PlacesRepository
+}
+
+func NewFetchImagesService(photoStorage port.Storage, placesRepo port.PlacesRepository) *FetchImagesService {
+	return &FetchImagesService{
+		photoStorage: photoStorage,
+		placesRepo:    placesRepo,
+	}
+}
+
+// FetchImages runs the pipeline to fetch and process images. Once ctx is done no
+// new photo is started, the photos in flight are abandoned without leaving temp
+// files behind, and a summary of what finished is logged.
+func (s *FetchImagesService) FetchImages(ctx context.Context, limit, offset int) error {
+	// Step 1: Producer - Fetch photos from Postgres
+	photos, err := s.placesRepo.GetPhotos(ctx, limit, offset)
+	if err != nil {
+		log.Printf("Failed to fetch photos: %v", err)
+		return err
+	}
+	log.Printf("Fetched %d photos from Postgres", len(photos))
+
+	// Step 2: Pipeline setup
+	photoChan := make(chan domain.Photo)
+	var stats imageStats
+	var wg sync.WaitGroup
+
+	// Start workers
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go s.worker(ctx, photoChan, &stats, &wg)
+	}
+
+	// Feed photos into the pipeline until they run out or the job is stopped
+	started := 0
+feed:
+	for _, photo := range photos {
+		select {
+		case photoChan <- photo:
+			started++
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(photoChan)
+
+	// Step 3: Wait for the photos in flight
+	wg.Wait()
+
+	log.Printf("Processed %d/%d photos: %d uploaded, %d already uploaded, %d failed, %d interrupted, %d not started",
+		started, len(photos), stats.uploaded, stats.skipped, stats.failed, stats.interrupted, len(photos)-started)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if stats.failed > 0 {
+		return fmt.Errorf("%d of %d photos failed", stats.failed, len(photos))
+	}
+	return nil
+}
+
+// imageStats counts how the photos of a run ended, updated atomically by the workers
+type imageStats struct {
+	uploaded    int64
+	skipped     int64 // Uploaded by an earlier run
+	failed      int64
+	interrupted int64 // Abandoned because the job was stopped
+}
+
+// worker processes photos in the pipeline
+func (s *FetchImagesService) worker(ctx context.Context, photoChan <-chan domain.Photo, stats *imageStats, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	// Clone the base collector for thread safety
+	collector := baseCollector.Clone()
+
+	for photo := range photoChan {
+		log.Printf("Worker processing photo for place %s, photo %s", photo.PlaceID, photo.PhotoId)
+
+		if photo.ImageUrl.Valid {
+			log.Printf("Photo already uploaded to storage: %s", photo.ImageUrl.String)
+			atomic.AddInt64(&stats.skipped, 1)
+			continue
+		}
+
+		err := s.processPhoto(ctx, photo, collector)
+		switch {
+		case err == nil:
+			atomic.AddInt64(&stats.uploaded, 1)
+		case ctx.Err() != nil:
+			atomic.AddInt64(&stats.interrupted, 1)
+		default:
+			log.Printf("Failed to process photo %s/%s: %v", photo.PlaceID, photo.PhotoId, err)
+			atomic.AddInt64(&stats.failed, 1)
+		}
+	}
+}
+
+// processPhoto scrapes, downloads, uploads and records one photo. The downloaded
+// file is removed however it ends.
+func (s *FetchImagesService) processPhoto(ctx context.Context, photo domain.Photo, collector *colly.Collector) error {
+	// Fetch image URL with random delay
+	imageURL, err := fetchImageURL(ctx, photo.FlagContentUri, collector)
+	if err != nil {
+		return fmt.Errorf("failed to fetch image URL for %s: %w", photo.FlagContentUri, err)
+	}
+
+	// Download image with random delay
+	imagePath, err := downloadImage(ctx, imageURL)
+	if err != nil {
+		return fmt.Errorf("failed to download image from %s: %w", imageURL, err)
+	}
+	defer os.Remove(imagePath)
+
+	// Upload image
+	imgURL, err := s.photoStorage.Upload(ctx, imagePath, "images/"+photo.PlaceID+"/"+photo.PhotoId+".jpg")
+	if err != nil {
+		return fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	// Update photo URL in Postgres
+	if err := s.placesRepo.UpdatePhotoURL(ctx, imgURL, photo.PlaceID, photo.PhotoId); err != nil {
+		return fmt.Errorf("failed to update photo URL: %w", err)
+	}
+
+	log.Printf("Successfully processed image for %s/%s, uploaded to %s", photo.PlaceID, photo.PhotoId, imgURL)
+	return nil
+}
+
+// randomDelay waits between minDelay and maxDelay to bypass rate limiting, or until ctx is done
+func randomDelay(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(minDelay + time.Duration(rand.Int63n(int64(maxDelay-minDelay)))):
+		return nil
+	}
+}
+
+// fetchImageURL fetches the image URL from the provided URI with a random delay
+func fetchImageURL(ctx context.Context, uri string, c *colly.Collector) (string, error) {
+	// Random delay to bypass rate limiting
+	if err := randomDelay(ctx); err != nil {
+		return "", err
+	}
+
+	log.Printf("Fetching image URL for %s", uri)
+	var imageURL string
+
+	c.OnHTML("img#preview-image", func(e *colly.HTMLElement) {
+		imageURL = e.Attr("src")
+		log.Printf("Found image URL: %s", imageURL)
+	})
+
+	c.OnError(func(r *colly.Response, err error) {
+		log.Printf("Error fetching %s: %v", uri, err)
+	})
+
+	err := c.Visit(uri)
+	if err != nil {
+		return "", err
+	}
+
+	if imageURL == "" {
+		return "", fmt.Errorf("no image URL found in %s", uri)
+	}
+
+	return imageURL, nil
+}
+
+// downloadImage downloads the image from the URL with a random delay into a temp
+// file, which is removed again if the download doesn't complete
+func downloadImage(ctx context.Context, imageURL string) (string, error) {
+	// Random delay to bypass rate limiting
+	if err := randomDelay(ctx); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	log.Printf("Downloading image from %s", imageURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download image from %s: status %d", imageURL, resp.StatusCode)
+	}
+
+	tempFile, err := os.CreateTemp("", "image-*.jpg")
+	if err != nil {
+		return "", err
+	}
+	defer tempFile.Close()
+
+	_, err = io.Copy(tempFile, resp.Body)
+	if err != nil {
+		os.Remove(tempFile.Name())
+		return "", err
+	}
+
+	log.Printf("Downloaded image to %s", tempFile.Name())
+	return tempFile.Name(), nil
 }
\ No newline at end of file
@@ -1,180 +1,466 @@
-package fetch
-
-import (
-	"context"
-	"log"
-	"math"
-	"sync"
-	"time"
-
-	"wheretoeat/internal/core/domain"
-	"wheretoeat/internal/core/port"
-)
-
-type FetchPlacesService struct {
-	placesRepo     port.SearchResultsRepository
-	categoriesRepo port.CategoriesRepository
-	apiAdapter     port.PlacesAPIPort
-
-	// Global variables for concurrency control
-	wg        sync.WaitGroup
-	errChan   chan error
-	semaphore chan struct{}
-}
-
-func NewFetchPlacesService(placesRepo port.SearchResultsRepository, categoriesRepo port.CategoriesRepository, apiAdapter port.PlacesAPIPort) *FetchPlacesService {
-	return &FetchPlacesService{
-		placesRepo:     placesRepo,
-		categoriesRepo: categoriesRepo,
-		apiAdapter:     apiAdapter,
-		errChan:        make(chan error), // Initialize channel
-		semaphore:      make(chan struct{}, 10), // Max 10 concurrent requests
-	}
-}
-
-func (s *FetchPlacesService) FetchPlaces(ctx context.Context, minLat, maxLat, minLng, maxLng float64, category string) error {
-	// Query config collection for types based on category
-	types, err := s.categoriesRepo.GetCategoryTypes(ctx, category)
-	if err != nil {
-		return err
-	}
-
-	// Generate initial grid of circles
-	circles := generateGrid(minLat, maxLat, minLng, maxLng)
-
-	// Resize error channel to match the number of circles
-	s.errChan = make(chan error, len(circles))
-
-	// Process each circle in a goroutine
-	for _, circle := range circles {
-		s.wg.Add(1)
-		go func(c domain.Circle) {
-			defer s.wg.Done()
-			// Acquire semaphore slot
-			s.semaphore <- struct{}{}
-			defer func() { <-s.semaphore }() // Release slot
-
-			if err := s.fetchPlacesForCircle(ctx, category, types, c); err != nil {
-				s.errChan <- err
-			}
-		}(circle)
-	}
-
-	// Wait for all goroutines to complete
-	s.wg.Wait()
-	close(s.errChan)
-
-	// Check for any errors
-	for err := range s.errChan {
-		if err != nil {
-			log.Printf("Error in goroutine: %v", err)
-			// Continue despite errors; return nil unless critical
-		}
-	}
-
-	return nil
-}
-
-// fetchPlacesForCircle handles fetching and subdividing for a single circle
-func (s *FetchPlacesService) fetchPlacesForCircle(ctx context.Context, category string, types []string, circle domain.Circle) error {
-    // Check if the circle already exists in the places collection
-    if circle.Radius < domain.MinRadius {
-        return nil
-    }
-
-    circleHasBeenScanned, err := s.placesRepo.AreaHasBeenScanned(ctx, category, circle)
-    if err != nil {
-        log.Printf("Failed to check if has fetched places for %s at area (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
-        return err
-    }
-    
-	numPlaces := int64(0)
-
-    // If circle exists, skip fetching but continue to check for subdivision
-    if !circleHasBeenScanned {
-        params := domain.RequestParams{
-            Types:  types,
-            Circle: circle,
-        }
-        time.Sleep(100 * time.Millisecond) // Rate limit API requests
-        places, err := s.apiAdapter.FetchPlaces(ctx, params)
-        if err != nil {
-            log.Printf("Failed to fetch places for %s at (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
-            return err
-        }
-        numPlaces = int64(len(places))
-        log.Printf("Fetched %d places for %s at (%.6f, %.6f, %.2fm)", numPlaces, category, circle.Lat, circle.Lng, circle.Radius)
-        
-        // Save fetched places
-        err = s.placesRepo.SaveSearchResults(ctx, category, circle, places)
-        if err != nil {
-            log.Printf("Failed to save places for %s at (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
-            return err
-        }
-        // Log all place names
-        for _, place := range places {
-            displayName, ok := place.(map[string]interface{})["displayName"].(map[string]interface{})["text"].(string)
-            if ok {
-                log.Printf("Place name: %s", displayName)
-            } else {
-                log.Printf("Failed to extract place name for a place in %s at (%.6f, %.6f, %.2fm)", category, circle.Lat, circle.Lng, circle.Radius)
-            }
-        }
-    } else {
-        numPlaces, err = s.placesRepo.GetNumPlaces(ctx, category, circle)
-        if err != nil {
-            log.Printf("Failed to get number of places for %s at (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
-        }
-    }
-    
-    // This indicates that the circle area has been scanned completely
-    if numPlaces < domain.MaxResultsPerReq {
-        log.Printf("Fetched less than %d places for %s at (%.6f, %.6f, %.2fm), skipping subdivision", domain.MaxResultsPerReq, category, circle.Lat, circle.Lng, circle.Radius)
-        return nil
-    }
-
-    // Subdivide the circle into smaller circles
-    newRadius := circle.Radius / 2
-    subCircles := subdivideCircle(circle.Lat, circle.Lng, circle.Radius, newRadius)
-    for _, subCircle := range subCircles {
-        if err := s.fetchPlacesForCircle(ctx, category, types, subCircle); err != nil {
-            log.Printf("Error in sub-circle for %s at (%.6f, %.6f, %.2fm): %v", category, subCircle.Lat, subCircle.Lng, subCircle.Radius, err)
-        }
-    }
-    return nil
-}
-
-// generateGrid creates a grid of circles for the specified area with dynamic radius
-func generateGrid(minLat, maxLat, minLng, maxLng float64) []domain.Circle {
-	latDistance := (maxLat - minLat) * 111320                    // meters N-S
-	lngDistance := (maxLng - minLng) * 111320 * domain.LngCosineAdjust // meters E-W (adjusted for latitude)
-
-	initialRadius := math.Min(latDistance, lngDistance)
-	if initialRadius < domain.MinRadius {
-		initialRadius = domain.MinRadius
-	}
-
-	latStep := initialRadius * 2 * domain.OverlapFactor * domain.LatMeterToDegree
-	lngStep := initialRadius * 2 * domain.OverlapFactor * domain.LatMeterToDegree / domain.LngCosineAdjust
-
-	var circles []domain.Circle
-	for lat := minLat; lat < maxLat + latStep/2; lat += latStep {
-		for lng := minLng; lng < maxLng + lngStep/2; lng += lngStep {
-			circles = append(circles, domain.Circle{Lat: lat, Lng: lng, Radius: initialRadius})
-		}
-	}	
-	return circles
-}
-
-// subdivideCircle splits a circle into smaller overlapping circles
-func subdivideCircle(lat, lng, radius, newRadius float64) []domain.Circle {
-	latStep := newRadius * domain.LatMeterToDegree
-	lngStep := newRadius * domain.LatMeterToDegree / domain.LngCosineAdjust
-
-	return []domain.Circle{
-		{Lat: lat - latStep, Lng: lng - lngStep, Radius: newRadius},
-		{Lat: lat - latStep, Lng: lng + lngStep, Radius: newRadius},
-		{Lat: lat + latStep, Lng: lng - lngStep, Radius: newRadius},
-		{Lat: lat + latStep, Lng: lng + lngStep, Radius: newRadius},
-	}
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// finishTimeout bounds recording a crawl job's outcome, which still runs once the crawl's context is done
+const finishTimeout = 30 * time.Second
+
+type FetchPlacesService struct {
+	placesRepo     port.SearchResultsRepository
+	categoriesRepo port.CategoriesRepository
+	crawlJobsRepo  port.CrawlJobsRepository
+	apiAdapter     port.PlacesAPIPort
+
+	// Global variables for concurrency control
+	wg        sync.WaitGroup
+	errChan   chan error
+	semaphore chan struct{}
+
+	// Crawl progress, reported once the crawl stops
+	jobID          string
+	boundary       domain.Boundary // Circles outside it are skipped, nil crawls the whole box
+	grid           domain.GridOptions
+	requests       int64
+	completedCells int64
+	stopped        atomic.Bool
+	stopOnce       sync.Once
+	stopErr        error // Why the crawl stopped early, see isStopError
+}
+
+func NewFetchPlacesService(placesRepo port.SearchResultsRepository, categoriesRepo port.CategoriesRepository, crawlJobsRepo port.CrawlJobsRepository, apiAdapter port.PlacesAPIPort) *FetchPlacesService {
+	return &FetchPlacesService{
+		placesRepo:     placesRepo,
+		categoriesRepo: categoriesRepo,
+		crawlJobsRepo:  crawlJobsRepo,
+		apiAdapter:     apiAdapter,
+		errChan:        make(chan error), // Initialize channel
+		semaphore:      make(chan struct{}, 10), // Max 10 cells crawled at once, requests are paced by the API rate limiter
+	}
+}
+
+// FetchPlaces starts a new crawl job over the bounding box, limited to the boundary if one is given.
+// The job's frontier is persisted as it goes, so an interrupted crawl can be picked up with ResumeFetchPlaces.
+func (s *FetchPlacesService) FetchPlaces(ctx context.Context, minLat, maxLat, minLng, maxLng float64, boundary domain.Boundary, grid domain.GridOptions, category string) (*domain.CrawlReport, error) {
+	if minLat >= maxLat || minLng >= maxLng {
+		return nil, fmt.Errorf("invalid bounding box: minLat %f and minLng %f must be less than maxLat %f and maxLng %f", minLat, minLng, maxLat, maxLng)
+	}
+	if err := grid.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Query config collection for types based on category
+	types, err := s.categoriesRepo.GetCategoryTypes(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate initial grid of circles, dropping those that miss the boundary
+	var circles []domain.Circle
+	for _, circle := range generateGrid(minLat, maxLat, minLng, maxLng, grid) {
+		if boundary.IntersectsCircle(circle) {
+			circles = append(circles, circle)
+		}
+	}
+
+	jobID, err := s.crawlJobsRepo.CreateJob(ctx, domain.CrawlJob{
+		Category:  category,
+		MinLat:    minLat,
+		MaxLat:    maxLat,
+		MinLng:    minLng,
+		MaxLng:    maxLng,
+		Boundary:  boundary,
+		Grid:      grid,
+		FieldMask: s.apiAdapter.FieldMask().Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.crawlJobsRepo.AddCircles(ctx, jobID, circles); err != nil {
+		return nil, err
+	}
+	log.Printf("Created crawl job %s with %d circles", jobID, len(circles))
+
+	s.boundary = boundary
+	s.grid = grid
+	return s.crawl(ctx, jobID, category, types, circles)
+}
+
+// PlanFetchPlaces works out the crawl FetchPlaces would start with the field mask, checking which
+// circles are already covered, without calling the Places API or creating a job
+func (s *FetchPlacesService) PlanFetchPlaces(ctx context.Context, minLat, maxLat, minLng, maxLng float64, boundary domain.Boundary, grid domain.GridOptions, fieldMask domain.FieldMask, category string) (*domain.CrawlPlan, error) {
+	if minLat >= maxLat || minLng >= maxLng {
+		return nil, fmt.Errorf("invalid bounding box: minLat %f and minLng %f must be less than maxLat %f and maxLng %f", minLat, minLng, maxLat, maxLng)
+	}
+	if err := grid.Validate(); err != nil {
+		return nil, err
+	}
+	// Fail early on unknown categories, like FetchPlaces
+	if _, err := s.categoriesRepo.GetCategoryTypes(ctx, category); err != nil {
+		return nil, err
+	}
+
+	plan := &domain.CrawlPlan{Category: category}
+	for _, circle := range generateGrid(minLat, maxLat, minLng, maxLng, grid) {
+		if !boundary.IntersectsCircle(circle) {
+			continue
+		}
+		scanned, err := s.placesRepo.AreaHasBeenScanned(ctx, category, circle, boundary, fieldMask.Level)
+		if err != nil {
+			return nil, err
+		}
+
+		// A scanned circle costs nothing unless it turns out to be dense and gets subdivided
+		worstCase := worstCaseRequests(circle.Radius, grid)
+		if scanned {
+			plan.ScannedCircles++
+			plan.WorstCaseRequests += worstCase - 1
+		} else {
+			plan.BestCaseRequests++
+			plan.WorstCaseRequests += worstCase
+		}
+		plan.Circles = append(plan.Circles, domain.PlannedCircle{Circle: circle, Scanned: scanned})
+	}
+	return plan, nil
+}
+
+// ResumeFetchPlaces continues a crawl job from its pending and failed circles
+func (s *FetchPlacesService) ResumeFetchPlaces(ctx context.Context, jobID string) (*domain.CrawlReport, error) {
+	job, err := s.crawlJobsRepo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("crawl job %s not found", jobID)
+	}
+
+	// Mixing masks within a job would leave circles with less data than the job promises
+	fieldMask, err := job.FieldMaskProfile()
+	if err != nil {
+		return nil, err
+	}
+	if s.apiAdapter.FieldMask().Name != fieldMask.Name {
+		return nil, fmt.Errorf("crawl job %s was started with the %s field mask, not %s", jobID, fieldMask.Name, s.apiAdapter.FieldMask().Name)
+	}
+
+	types, err := s.categoriesRepo.GetCategoryTypes(ctx, job.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	unfinished, err := s.crawlJobsRepo.GetUnfinishedCircles(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	circles := make([]domain.Circle, len(unfinished))
+	for i, c := range unfinished {
+		circles[i] = c.Circle()
+	}
+	log.Printf("Resuming crawl job %s for %s with %d unfinished circles", jobID, job.Category, len(circles))
+
+	if err := s.crawlJobsRepo.UpdateJobStatus(ctx, jobID, domain.CrawlJobRunning); err != nil {
+		return nil, err
+	}
+	s.boundary = job.Boundary
+	s.grid = job.Grid
+	if s.grid == (domain.GridOptions{}) {
+		s.grid = domain.DefaultGridOptions() // Jobs created before the grid was configurable
+	}
+	return s.crawl(ctx, jobID, job.Category, types, circles)
+}
+
+// crawl fetches every circle (and its subdivisions) concurrently and records the job's outcome
+func (s *FetchPlacesService) crawl(ctx context.Context, jobID string, category string, types []string, circles []domain.Circle) (*domain.CrawlReport, error) {
+	s.jobID = jobID
+
+	// Resize error channel to match the number of circles
+	s.errChan = make(chan error, len(circles))
+
+	// Process each circle in a goroutine
+	for _, circle := range circles {
+		s.wg.Add(1)
+		go func(c domain.Circle) {
+			defer s.wg.Done()
+			// Acquire semaphore slot
+			s.semaphore <- struct{}{}
+			defer func() { <-s.semaphore }() // Release slot
+
+			// Once the crawl is stopped, remaining cells are left for a later run
+			if s.stopped.Load() || ctx.Err() != nil {
+				return
+			}
+			if err := s.fetchPlacesForCircle(ctx, category, types, c); err != nil {
+				if isStopError(err) || ctx.Err() != nil {
+					s.stop(err)
+				}
+				s.errChan <- err
+				return
+			}
+			atomic.AddInt64(&s.completedCells, 1)
+		}(circle)
+	}
+
+	// Wait for all goroutines to complete
+	s.wg.Wait()
+	close(s.errChan)
+
+	// Check for any errors
+	for err := range s.errChan {
+		if err != nil && !isStopError(err) && ctx.Err() == nil {
+			log.Printf("Error in goroutine: %v", err)
+			// Continue despite errors; return nil unless critical
+		}
+	}
+
+	// The job is recorded even if ctx is done, so it can be resumed
+	finishCtx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+	unfinished, err := s.crawlJobsRepo.GetUnfinishedCircles(finishCtx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	status := domain.CrawlJobCompleted
+	if len(unfinished) > 0 {
+		status = domain.CrawlJobIncomplete
+	}
+	if err := s.crawlJobsRepo.UpdateJobStatus(finishCtx, jobID, status); err != nil {
+		return nil, err
+	}
+
+	report := &domain.CrawlReport{
+		JobID:           jobID,
+		Category:        category,
+		TotalCells:      len(circles),
+		CompletedCells:  int(atomic.LoadInt64(&s.completedCells)),
+		PendingCircles:  len(unfinished),
+		Requests:        int(atomic.LoadInt64(&s.requests)),
+		BudgetExhausted: errors.Is(s.stopErr, domain.ErrBudgetExhausted),
+		Interrupted:     ctx.Err() != nil,
+	}
+	log.Printf("Crawled %d/%d cells (%.1f%%) for %s with %d requests, job %s is %s with %d circles left",
+		report.CompletedCells, report.TotalCells, report.CoveragePercent(), category, report.Requests,
+		jobID, status, report.PendingCircles)
+
+	// Running out of budget is a clean stop, anything else fails the job
+	if report.Interrupted {
+		return report, ctx.Err()
+	}
+	if s.stopErr != nil && !report.BudgetExhausted {
+		return report, s.stopErr
+	}
+	return report, nil
+}
+
+// isStopError reports whether err makes every further request pointless:
+// the budget is exhausted or the API key is rejected
+func isStopError(err error) bool {
+	return errors.Is(err, domain.ErrBudgetExhausted) || errors.Is(err, domain.ErrPlacesAPIAuth)
+}
+
+// stop makes the remaining cells skip their requests
+func (s *FetchPlacesService) stop(err error) {
+	s.stopOnce.Do(func() {
+		log.Printf("Stopping crawl: %v", err)
+		s.stopErr = err
+		s.stopped.Store(true)
+	})
+}
+
+// fetchPlacesForCircle handles fetching and subdividing for a single circle.
+// Sub-circles are checkpointed to the job's frontier before the circle is marked
+// done, so no work is lost if the crawl is interrupted.
+func (s *FetchPlacesService) fetchPlacesForCircle(ctx context.Context, category string, types []string, circle domain.Circle) error {
+	if circle.Radius < s.grid.MinRadius {
+		return nil
+	}
+
+	numPlaces, err := s.scanCircle(ctx, category, types, circle)
+	if err != nil {
+		// Stopped circles stay pending, other failures are retried on resume
+		if !isStopError(err) && ctx.Err() == nil {
+			s.markCircle(ctx, circle, domain.CrawlCircleFailed, 0, err)
+		}
+		return err
+	}
+
+	// This indicates that the circle area has been scanned completely
+	if numPlaces < domain.MaxResultsPerReq {
+		log.Printf("Fetched less than %d places for %s at (%.6f, %.6f, %.2fm), skipping subdivision", domain.MaxResultsPerReq, category, circle.Lat, circle.Lng, circle.Radius)
+		return s.markCircle(ctx, circle, domain.CrawlCircleDone, numPlaces, nil)
+	}
+
+	// Subdivide the circle into smaller circles
+	var subCircles []domain.Circle
+	for _, subCircle := range subdivideCircle(circle, s.grid) {
+		if subCircle.Radius >= s.grid.MinRadius && s.boundary.IntersectsCircle(subCircle) {
+			subCircles = append(subCircles, subCircle)
+		}
+	}
+	if err := s.crawlJobsRepo.AddCircles(ctx, s.jobID, subCircles); err != nil {
+		return err
+	}
+	if err := s.markCircle(ctx, circle, domain.CrawlCircleDone, numPlaces, nil); err != nil {
+		return err
+	}
+
+	for _, subCircle := range subCircles {
+		if err := s.fetchPlacesForCircle(ctx, category, types, subCircle); err != nil {
+			// Without budget or a valid key the rest of the circle can't be covered, so it isn't complete
+			if isStopError(err) || ctx.Err() != nil {
+				return err
+			}
+			log.Printf("Error in sub-circle for %s at (%.6f, %.6f, %.2fm): %v", category, subCircle.Lat, subCircle.Lng, subCircle.Radius, err)
+		}
+	}
+	return nil
+}
+
+// scanCircle fetches and saves the places of a circle, unless it has been
+// scanned before, and returns how many places the circle has
+func (s *FetchPlacesService) scanCircle(ctx context.Context, category string, types []string, circle domain.Circle) (int64, error) {
+	// Check if the circle already exists in the places collection
+	// Circles crawled with fewer fields than this crawl asks for are crawled again
+	circleHasBeenScanned, err := s.placesRepo.AreaHasBeenScanned(ctx, category, circle, s.boundary, s.apiAdapter.FieldMask().Level)
+	if err != nil {
+		log.Printf("Failed to check if has fetched places for %s at area (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
+		return 0, err
+	}
+
+	// If circle exists, skip fetching but continue to check for subdivision
+	if circleHasBeenScanned {
+		numPlaces, err := s.placesRepo.GetNumPlaces(ctx, category, circle)
+		if err != nil {
+			log.Printf("Failed to get number of places for %s at (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
+		}
+		return numPlaces, nil
+	}
+
+	params := domain.RequestParams{
+		Types:  types,
+		Circle: circle,
+	}
+	places, err := s.apiAdapter.FetchPlaces(ctx, params)
+	if isStopError(err) {
+		return 0, err
+	}
+	if err != nil {
+		log.Printf("Failed to fetch places for %s at (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
+		return 0, err
+	}
+	atomic.AddInt64(&s.requests, 1)
+	numPlaces := int64(len(places))
+	log.Printf("Fetched %d places for %s at (%.6f, %.6f, %.2fm)", numPlaces, category, circle.Lat, circle.Lng, circle.Radius)
+
+	// Save fetched places
+	err = s.placesRepo.SaveSearchResults(ctx, category, circle, s.apiAdapter.FieldMask(), places)
+	if err != nil {
+		log.Printf("Failed to save places for %s at (%.6f, %.6f, %.2fm): %v", category, circle.Lat, circle.Lng, circle.Radius, err)
+		return 0, err
+	}
+	// Log all place names
+	for _, place := range places {
+		if place.Place.DisplayName != nil {
+			log.Printf("Place name: %s", place.Place.DisplayName.Text)
+		} else {
+			log.Printf("Place %s in %s at (%.6f, %.6f, %.2fm) has no name", place.Place.ID, category, circle.Lat, circle.Lng, circle.Radius)
+		}
+	}
+	return numPlaces, nil
+}
+
+// markCircle checkpoints the status of a circle in the job's frontier
+func (s *FetchPlacesService) markCircle(ctx context.Context, circle domain.Circle, status string, numPlaces int64, cause error) error {
+	errMsg := ""
+	if cause != nil {
+		errMsg = cause.Error()
+	}
+	err := s.crawlJobsRepo.MarkCircle(ctx, s.jobID, circle, status, numPlaces, errMsg)
+	if err != nil {
+		log.Printf("Failed to mark circle (%.6f, %.6f, %.2fm) as %s: %v", circle.Lat, circle.Lng, circle.Radius, status, err)
+	}
+	return err
+}
+
+// generateGrid covers the bounding box with rows of equal circles, sized after the box's
+// shorter side. The box is cut into cells no larger than the square a circle covers with
+// grid.Overlap to spare, measured at each row's edge nearest the equator where a degree of
+// longitude is longest, so the circles cover the whole box at any latitude.
+func generateGrid(minLat, maxLat, minLng, maxLng float64, grid domain.GridOptions) []domain.Circle {
+	latDistance := (maxLat - minLat) / domain.LatMeterToDegree                          // meters N-S
+	lngDistance := (maxLng - minLng) / domain.LngMeterToDegree(nearestToEquator(minLat, maxLat)) // meters E-W
+
+	radius := math.Min(latDistance, lngDistance)
+	radius = math.Max(radius, grid.MinRadius)
+	radius = math.Min(radius, domain.MaxSearchRadius)
+	cellSize := radius * math.Sqrt2 / (1 + grid.Overlap)
+
+	var circles []domain.Circle
+	rows := int(math.Ceil(latDistance / cellSize))
+	rowHeight := (maxLat - minLat) / float64(rows)
+	for i := 0; i < rows; i++ {
+		south := minLat + float64(i)*rowHeight
+		rowWidth := (maxLng - minLng) / domain.LngMeterToDegree(nearestToEquator(south, south+rowHeight))
+		cols := int(math.Ceil(rowWidth / cellSize))
+		colWidth := (maxLng - minLng) / float64(cols)
+		for j := 0; j < cols; j++ {
+			circles = append(circles, domain.Circle{
+				Lat:    south + rowHeight/2,
+				Lng:    minLng + (float64(j)+0.5)*colWidth,
+				Radius: radius,
+			})
+		}
+	}
+	return circles
+}
+
+// nearestToEquator returns the latitude between south and north closest to the equator
+func nearestToEquator(south, north float64) float64 {
+	switch {
+	case south > 0:
+		return south
+	case north < 0:
+		return north
+	}
+	return 0
+}
+
+// worstCaseRequests counts the requests for a circle that is dense all the way down to grid.MinRadius.
+// Every level has seven times the circles of the one above, so it is counted in float64.
+func worstCaseRequests(radius float64, grid domain.GridOptions) float64 {
+	requests, circles := 0.0, 1.0
+	for depth := grid.SubdivisionDepth(radius); depth > 0; depth-- {
+		requests += circles
+		circles *= 7
+	}
+	return requests
+}
+
+// subdivideCircle splits a circle into seven smaller overlapping circles, one in the
+// middle and six around it, which together cover the whole circle. Four circles of half
+// the radius can't, they leave gaps along the rim.
+func subdivideCircle(circle domain.Circle, grid domain.GridOptions) []domain.Circle {
+	newRadius := grid.SubRadius(circle.Radius)
+	ringDistance := circle.Radius * math.Sqrt(3) / 2
+
+	subCircles := []domain.Circle{{Lat: circle.Lat, Lng: circle.Lng, Radius: newRadius}}
+	for i := 0; i < 6; i++ {
+		angle := float64(i) * math.Pi / 3
+		subCircles = append(subCircles, domain.Circle{
+			Lat:    circle.Lat + ringDistance*math.Sin(angle)*domain.LatMeterToDegree,
+			Lng:    circle.Lng + ringDistance*math.Cos(angle)*domain.LngMeterToDegree(circle.Lat),
+			Radius: newRadius,
+		})
+	}
+	return subCircles
 }
\ No newline at end of file
@@ -0,0 +1,379 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"wheretoeat/internal/adapter/fake"
+	"wheretoeat/internal/core/domain"
+)
+
+// testLatitudes run from the equator to far enough north that a degree of longitude is half as long
+var testLatitudes = []float64{0, 10.7769, 21.0285, 45.764, -33.8688, 59.9139}
+
+// nearestCircle returns the distance from p to the center of the circle that covers it best,
+// relative to that circle's radius
+func nearestCircle(p domain.LatLng, circles []domain.Circle) float64 {
+	best := -1.0
+	for _, c := range circles {
+		d := domain.DistanceMeters(p, domain.LatLng{Lat: c.Lat, Lng: c.Lng}) / c.Radius
+		if best < 0 || d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+func TestGenerateGridCoversBox(t *testing.T) {
+	sizes := []struct {
+		name                      string
+		heightMeters, widthMeters float64
+	}{
+		{"square district", 3000, 3000},
+		{"wide district", 2000, 7000},
+		{"tall city", 20000, 8000},
+		{"province larger than the max radius", 150000, 200000},
+	}
+
+	for _, lat := range testLatitudes {
+		for _, size := range sizes {
+			t.Run(fmt.Sprintf("%s at latitude %.2f", size.name, lat), func(t *testing.T) {
+				grid := domain.DefaultGridOptions()
+				minLat, minLng := lat, 106.6
+				maxLat := minLat + size.heightMeters*domain.LatMeterToDegree
+				maxLng := minLng + size.widthMeters*domain.LngMeterToDegree(lat)
+
+				circles := generateGrid(minLat, maxLat, minLng, maxLng, grid)
+				if len(circles) == 0 {
+					t.Fatal("no circles")
+				}
+				for _, c := range circles {
+					if c.Radius > domain.MaxSearchRadius || c.Radius < grid.MinRadius {
+						t.Fatalf("radius %.0f outside [%.0f, %.0f]", c.Radius, grid.MinRadius, float64(domain.MaxSearchRadius))
+					}
+				}
+
+				const steps = 40
+				for i := 0; i <= steps; i++ {
+					for j := 0; j <= steps; j++ {
+						p := domain.LatLng{
+							Lat: minLat + (maxLat-minLat)*float64(i)/steps,
+							Lng: minLng + (maxLng-minLng)*float64(j)/steps,
+						}
+						if d := nearestCircle(p, circles); d > 1 {
+							t.Fatalf("point (%.6f, %.6f) is %.2f radii from the nearest of %d circles", p.Lat, p.Lng, d, len(circles))
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSubdivideCircleCoversCircle(t *testing.T) {
+	grids := []domain.GridOptions{
+		domain.DefaultGridOptions(),
+		{Overlap: 0.05, MinRadius: 50},
+		{Overlap: 0.5, MinRadius: 50},
+	}
+
+	for _, lat := range testLatitudes {
+		for _, radius := range []float64{domain.MaxSearchRadius, 2000, 100} {
+			for _, grid := range grids {
+				t.Run(fmt.Sprintf("radius %.0f overlap %.2f at latitude %.2f", radius, grid.Overlap, lat), func(t *testing.T) {
+					circle := domain.Circle{Lat: lat, Lng: 106.6, Radius: radius}
+					subCircles := subdivideCircle(circle, grid)
+					if len(subCircles) != 7 {
+						t.Fatalf("got %d sub-circles, want 7", len(subCircles))
+					}
+					for _, sub := range subCircles {
+						if sub.Radius != grid.SubRadius(radius) || sub.Radius >= radius {
+							t.Fatalf("sub-circle radius %.2f, want %.2f", sub.Radius, grid.SubRadius(radius))
+						}
+					}
+					if covered := domain.CoveredFraction(circle, nil, subCircles); covered < 1 {
+						t.Errorf("sub-circles cover %.6f of the circle", covered)
+					}
+				})
+			}
+		}
+	}
+}
+
+// memSearchResults keeps search results in memory and decides coverage with the same
+// domain rule as the MongoDB repository
+type memSearchResults struct {
+	mu      sync.Mutex
+	circles []domain.SearchCircle
+}
+
+func (r *memSearchResults) SaveSearchResults(ctx context.Context, category string, circle domain.Circle, fieldMask domain.FieldMask, places []domain.PlaceResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := domain.SearchCircle{Category: category, Circle: circle, FieldMask: fieldMask.Name}
+	for _, place := range places {
+		saved.PlaceIDs = append(saved.PlaceIDs, place.Place.ID)
+	}
+	r.circles = append(r.circles, saved)
+	return nil
+}
+
+func (r *memSearchResults) AreaHasBeenScanned(ctx context.Context, category string, circle domain.Circle, boundary domain.Boundary, fieldLevel int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return domain.ScannedFraction(circle, boundary, r.circles, fieldLevel) >= 1, nil
+}
+
+func (r *memSearchResults) GetNumPlaces(ctx context.Context, category string, circle domain.Circle) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, saved := range r.circles {
+		if saved.Circle == circle {
+			return int64(len(saved.PlaceIDs)), nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *memSearchResults) SaveImportedPlaces(ctx context.Context, provider, source, category string, places []interface{}) error {
+	return nil
+}
+
+func (r *memSearchResults) DeleteImportedPlaces(ctx context.Context, provider, source string) error {
+	return nil
+}
+
+func (r *memSearchResults) SaveTextSearchResults(ctx context.Context, query, category string, circle domain.Circle, fieldMask domain.FieldMask, places []domain.PlaceResult) error {
+	return nil
+}
+
+func (r *memSearchResults) GetTextSearchNumPlaces(ctx context.Context, query string, circle domain.Circle, fieldLevel int) (int64, bool, error) {
+	return 0, false, nil
+}
+
+func (r *memSearchResults) GetStaleCircles(ctx context.Context, category string, fieldMask domain.FieldMask, olderThan time.Time, limit int) ([]domain.SearchCircle, error) {
+	return nil, nil
+}
+
+func (r *memSearchResults) GetSearchCircles(ctx context.Context, category string) ([]domain.SearchCircle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.SearchCircle(nil), r.circles...), nil
+}
+
+func (r *memSearchResults) UpdateSearchResults(ctx context.Context, id string, places []domain.PlaceResult) error {
+	return nil
+}
+
+type memCategories map[string][]string
+
+func (c memCategories) GetCategoryTypes(ctx context.Context, category string) ([]string, error) {
+	types, ok := c[category]
+	if !ok {
+		return nil, fmt.Errorf("unknown category %s", category)
+	}
+	return types, nil
+}
+
+func (c memCategories) ListCategories(ctx context.Context) ([]string, error) {
+	var categories []string
+	for category := range c {
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+// memCrawlJobs keeps the frontier of crawl jobs in memory
+type memCrawlJobs struct {
+	mu      sync.Mutex
+	jobs    map[string]*domain.CrawlJob
+	circles map[string][]*domain.CrawlCircle
+}
+
+func newMemCrawlJobs() *memCrawlJobs {
+	return &memCrawlJobs{jobs: make(map[string]*domain.CrawlJob), circles: make(map[string][]*domain.CrawlCircle)}
+}
+
+func (r *memCrawlJobs) CreateJob(ctx context.Context, job domain.CrawlJob) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobID := fmt.Sprintf("job-%d", len(r.jobs)+1)
+	job.Status = domain.CrawlJobRunning
+	r.jobs[jobID] = &job
+	return jobID, nil
+}
+
+func (r *memCrawlJobs) GetJob(ctx context.Context, jobID string) (*domain.CrawlJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jobs[jobID], nil
+}
+
+func (r *memCrawlJobs) UpdateJobStatus(ctx context.Context, jobID string, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.jobs[jobID].Status = status
+	return nil
+}
+
+func (r *memCrawlJobs) AddCircles(ctx context.Context, jobID string, circles []domain.Circle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range circles {
+		if r.find(jobID, c) == nil {
+			r.circles[jobID] = append(r.circles[jobID], &domain.CrawlCircle{Lat: c.Lat, Lng: c.Lng, Radius: c.Radius, Status: domain.CrawlCirclePending})
+		}
+	}
+	return nil
+}
+
+func (r *memCrawlJobs) MarkCircle(ctx context.Context, jobID string, circle domain.Circle, status string, numPlaces int64, errMsg string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := r.find(jobID, circle)
+	if c == nil {
+		return fmt.Errorf("circle %v is not in job %s", circle, jobID)
+	}
+	c.Status, c.NumPlaces, c.Error = status, numPlaces, errMsg
+	return nil
+}
+
+func (r *memCrawlJobs) GetUnfinishedCircles(ctx context.Context, jobID string) ([]domain.CrawlCircle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unfinished []domain.CrawlCircle
+	for _, c := range r.circles[jobID] {
+		if c.Status != domain.CrawlCircleDone {
+			unfinished = append(unfinished, *c)
+		}
+	}
+	return unfinished, nil
+}
+
+func (r *memCrawlJobs) find(jobID string, circle domain.Circle) *domain.CrawlCircle {
+	for _, c := range r.circles[jobID] {
+		if c.Circle() == circle {
+			return c
+		}
+	}
+	return nil
+}
+
+// latticePlaces lays restaurants every spacing meters over a square of side meters
+// centered on center, so every circle wider than a few spacings hits the result cap
+func latticePlaces(center domain.LatLng, side, spacing float64) []map[string]interface{} {
+	var places []map[string]interface{}
+	n := int(side / spacing)
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= n; j++ {
+			y, x := float64(i)*spacing-side/2, float64(j)*spacing-side/2
+			places = append(places, map[string]interface{}{
+				"id":          fmt.Sprintf("lattice-%d-%d", i, j),
+				"displayName": map[string]interface{}{"text": fmt.Sprintf("Restaurant %d-%d", i, j)},
+				"primaryType": "restaurant",
+				"location": map[string]interface{}{
+					"latitude":  center.Lat + y*domain.LatMeterToDegree,
+					"longitude": center.Lng + x*domain.LngMeterToDegree(center.Lat),
+				},
+			})
+		}
+	}
+	return places
+}
+
+func TestFetchPlacesSubdividesSaturatedCircles(t *testing.T) {
+	center := domain.LatLng{Lat: 10.7769, Lng: 106.7009}
+	const side = 2000.0
+	places := latticePlaces(center, side, 100)
+	minLat, maxLat := center.Lat-side/2*domain.LatMeterToDegree, center.Lat+side/2*domain.LatMeterToDegree
+	minLng, maxLng := center.Lng-side/2*domain.LngMeterToDegree(center.Lat), center.Lng+side/2*domain.LngMeterToDegree(center.Lat)
+
+	searchResults := &memSearchResults{}
+	categories := memCategories{"restaurants": {"restaurant"}}
+	crawlJobs := newMemCrawlJobs()
+	api := fake.NewFakePlacesAPI(domain.FieldMaskBasic, places)
+	grid := domain.DefaultGridOptions()
+
+	service := NewFetchPlacesService(searchResults, categories, crawlJobs, api)
+	report, err := service.FetchPlaces(context.Background(), minLat, maxLat, minLng, maxLng, nil, grid, "restaurants")
+	if err != nil {
+		t.Fatalf("crawl failed: %v", err)
+	}
+	if report.CompletedCells != report.TotalCells || report.PendingCircles != 0 {
+		t.Errorf("crawl left cells behind: %+v", report)
+	}
+
+	// Every place is only reachable through circles small enough to stay under the cap
+	found := make(map[string]bool)
+	stored, _ := searchResults.GetSearchCircles(context.Background(), "restaurants")
+	for _, c := range stored {
+		for _, id := range c.PlaceIDs {
+			found[id] = true
+		}
+	}
+	if len(found) != len(places) {
+		t.Errorf("crawl found %d of %d places", len(found), len(places))
+	}
+
+	// Every saturated circle was split and every part of it crawled
+	saturated := 0
+	for _, c := range stored {
+		if !c.Saturated() || grid.SubRadius(c.Circle.Radius) < grid.MinRadius {
+			continue
+		}
+		saturated++
+		for _, sub := range subdivideCircle(c.Circle, grid) {
+			frontier := crawlJobs.find(report.JobID, sub)
+			if frontier == nil || frontier.Status != domain.CrawlCircleDone {
+				t.Errorf("sub-circle %v of saturated circle %v was not crawled", sub, c.Circle)
+			}
+		}
+	}
+	if saturated == 0 {
+		t.Fatal("no circle hit the result cap, the dataset is too sparse")
+	}
+
+	// A second crawl of the same box finds it covered by the sub-circles and sends nothing
+	calls := len(api.Calls())
+	again := NewFetchPlacesService(searchResults, categories, crawlJobs, api)
+	report, err = again.FetchPlaces(context.Background(), minLat, maxLat, minLng, maxLng, nil, grid, "restaurants")
+	if err != nil {
+		t.Fatalf("second crawl failed: %v", err)
+	}
+	if report.Requests != 0 || len(api.Calls()) != calls {
+		t.Errorf("second crawl made %d requests, want 0", len(api.Calls())-calls)
+	}
+}
+
+func TestWorstCaseRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		radius float64
+		grid   domain.GridOptions
+		want   float64
+	}{
+		{"below the minimum radius", 40, domain.DefaultGridOptions(), 0},
+		{"at the minimum radius", 50, domain.DefaultGridOptions(), 1},
+		{"one subdivision", 100, domain.DefaultGridOptions(), 1 + 7},
+		{"largest circle", domain.MaxSearchRadius, domain.DefaultGridOptions(), (math.Pow(7, 12) - 1) / 6},
+		{"no overlap", 400, domain.GridOptions{MinRadius: 50}, 1 + 7 + 49 + 343},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := worstCaseRequests(tt.radius, tt.grid); got != tt.want {
+				t.Errorf("got %.0f, want %.0f", got, tt.want)
+			}
+		})
+	}
+
+	// Grids Validate rejects must still give a finite count rather than overflow
+	deep := domain.GridOptions{Overlap: 0.99, MinRadius: 1}
+	if got := worstCaseRequests(domain.MaxSearchRadius, deep); math.IsInf(got, 0) || got <= 0 {
+		t.Errorf("got %g for a grid %d levels deep", got, deep.SubdivisionDepth(domain.MaxSearchRadius))
+	}
+}
\ No newline at end of file
@@ -0,0 +1,158 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// FetchTextService crawls an area with a Text Search query, for places such as
+// street-food vendors that only turn up by name or dish and not by place type
+type FetchTextService struct {
+	placesRepo port.SearchResultsRepository
+	apiAdapter port.TextSearchAPIPort
+
+	// Crawl progress, reported once the crawl stops
+	boundary domain.Boundary
+	grid     domain.GridOptions
+	requests int
+}
+
+func NewFetchTextService(placesRepo port.SearchResultsRepository, apiAdapter port.TextSearchAPIPort) *FetchTextService {
+	return &FetchTextService{placesRepo: placesRepo, apiAdapter: apiAdapter}
+}
+
+// FetchText runs the query over every cell of the bounding box that touches the boundary,
+// following nextPageToken up to MaxTextSearchResults places per cell. A cell that hits
+// the maximum is split into quarters, down to grid.MinRadius. Results are saved under
+// category, which may be empty, with the query recorded.
+func (s *FetchTextService) FetchText(ctx context.Context, minLat, maxLat, minLng, maxLng float64, boundary domain.Boundary, grid domain.GridOptions, query, category string) (*domain.CrawlReport, error) {
+	if minLat >= maxLat || minLng >= maxLng {
+		return nil, fmt.Errorf("invalid bounding box: minLat %f and minLng %f must be less than maxLat %f and maxLng %f", minLat, minLng, maxLat, maxLng)
+	}
+	if query == "" {
+		return nil, fmt.Errorf("text search needs a query")
+	}
+	if err := grid.Validate(); err != nil {
+		return nil, err
+	}
+
+	var cells []domain.Circle
+	for _, circle := range generateGrid(minLat, maxLat, minLng, maxLng, grid) {
+		if boundary.IntersectsCircle(circle) {
+			cells = append(cells, circle)
+		}
+	}
+	log.Printf("Searching '%s' in %d cells", query, len(cells))
+
+	s.boundary = boundary
+	s.grid = grid
+	report := &domain.CrawlReport{Category: category, TotalCells: len(cells)}
+	var stopErr error
+	for _, cell := range cells {
+		err := s.searchCell(ctx, query, category, cell)
+		if ctx.Err() != nil {
+			stopErr = ctx.Err()
+			break
+		}
+		if isStopError(err) {
+			stopErr = err
+			break
+		}
+		if err != nil {
+			log.Printf("Error searching '%s' at (%.6f, %.6f, %.2fm): %v", query, cell.Lat, cell.Lng, cell.Radius, err)
+			continue
+		}
+		report.CompletedCells++
+	}
+
+	report.Requests = s.requests
+	report.BudgetExhausted = errors.Is(stopErr, domain.ErrBudgetExhausted)
+	report.Interrupted = ctx.Err() != nil
+	log.Printf("Searched %d/%d cells (%.1f%%) for '%s' with %d requests",
+		report.CompletedCells, report.TotalCells, report.CoveragePercent(), query, report.Requests)
+
+	// Running out of budget is a clean stop, anything else fails the crawl
+	if stopErr != nil && !report.BudgetExhausted {
+		return report, stopErr
+	}
+	return report, nil
+}
+
+// searchCell searches one cell, unless it was searched before, and its subdivisions if it's full
+func (s *FetchTextService) searchCell(ctx context.Context, query, category string, cell domain.Circle) error {
+	if cell.Radius < s.grid.MinRadius {
+		return nil
+	}
+
+	fieldMask := s.apiAdapter.FieldMask()
+	numPlaces, searched, err := s.placesRepo.GetTextSearchNumPlaces(ctx, query, cell, fieldMask.Level)
+	if err != nil {
+		return err
+	}
+	if !searched {
+		places, err := s.searchAllPages(ctx, query, cell)
+		if err != nil {
+			return err
+		}
+		if err := s.placesRepo.SaveTextSearchResults(ctx, query, category, cell, fieldMask, places); err != nil {
+			return err
+		}
+		numPlaces = int64(len(places))
+		log.Printf("Found %d places for '%s' at (%.6f, %.6f, %.2fm)", numPlaces, query, cell.Lat, cell.Lng, cell.Radius)
+	}
+
+	if numPlaces < domain.MaxTextSearchResults {
+		return nil
+	}
+	for _, subCell := range quarterCell(cell) {
+		if !s.boundary.IntersectsCircle(subCell) {
+			continue
+		}
+		if err := s.searchCell(ctx, query, category, subCell); err != nil {
+			if isStopError(err) {
+				return err
+			}
+			log.Printf("Error in sub-cell for '%s' at (%.6f, %.6f, %.2fm): %v", query, subCell.Lat, subCell.Lng, subCell.Radius, err)
+		}
+	}
+	return nil
+}
+
+// searchAllPages follows nextPageToken until the last page or MaxTextSearchResults places
+func (s *FetchTextService) searchAllPages(ctx context.Context, query string, cell domain.Circle) ([]domain.PlaceResult, error) {
+	var places []domain.PlaceResult
+	params := domain.RequestParams{Query: query, Circle: cell}
+	for len(places) < domain.MaxTextSearchResults {
+		page, nextPageToken, err := s.apiAdapter.SearchText(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		s.requests++
+		places = append(places, page...)
+		if nextPageToken == "" {
+			break
+		}
+		params.PageToken = nextPageToken
+	}
+	return places, nil
+}
+
+// quarterCell splits a cell into its four quarters. Text Search is restricted to the
+// cell's bounding square rather than its circle, so the quarters cover it exactly.
+func quarterCell(cell domain.Circle) []domain.Circle {
+	newRadius := cell.Radius / 2
+	latStep := newRadius * domain.LatMeterToDegree
+	lngStep := newRadius * domain.LngMeterToDegree(cell.Lat)
+
+	return []domain.Circle{
+		{Lat: cell.Lat - latStep, Lng: cell.Lng - lngStep, Radius: newRadius},
+		{Lat: cell.Lat - latStep, Lng: cell.Lng + lngStep, Radius: newRadius},
+		{Lat: cell.Lat + latStep, Lng: cell.Lng - lngStep, Radius: newRadius},
+		{Lat: cell.Lat + latStep, Lng: cell.Lng + lngStep, Radius: newRadius},
+	}
+}
\ No newline at end of file
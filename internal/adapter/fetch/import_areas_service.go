@@ -0,0 +1,37 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// ImportAreasService saves cities, districts and wards with their boundaries, for
+// example read from a GeoJSON file, and links them into a hierarchy
+type ImportAreasService struct {
+	areasRepo port.AreasRepository
+}
+
+func NewImportAreasService(areasRepo port.AreasRepository) *ImportAreasService {
+	return &ImportAreasService{areasRepo: areasRepo}
+}
+
+// ImportAreas replaces the saved copies of the areas, then links every saved area to its parent
+func (s *ImportAreasService) ImportAreas(ctx context.Context, areas []domain.Area) error {
+	for _, area := range areas {
+		if area.PlaceID == "" || area.Name == "" || len(area.Boundary) == 0 {
+			return fmt.Errorf("area %q needs an ID, a name and a boundary", area.Name)
+		}
+		area.MinLat, area.MaxLat, area.MinLng, area.MaxLng = area.Boundary.BoundingBox()
+		area.Timestamp = time.Now()
+		if err := s.areasRepo.UpsertArea(ctx, area); err != nil {
+			return err
+		}
+		log.Printf("Imported %s %s with a boundary of %d polygons", area.Level, area.Name, len(area.Boundary))
+	}
+	return linkAreaParents(ctx, s.areasRepo)
+}
\ No newline at end of file
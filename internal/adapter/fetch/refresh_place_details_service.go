@@ -0,0 +1,82 @@
+package fetch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// RefreshPlaceDetailsService fetches single places again with Place Details and writes
+// them straight to PostgreSQL, for when a place's hours are wrong or it has closed
+type RefreshPlaceDetailsService struct {
+	placesRepo port.PlacesRepository
+	detailsAPI port.PlaceDetailsAPIPort
+}
+
+func NewRefreshPlaceDetailsService(placesRepo port.PlacesRepository, detailsAPI port.PlaceDetailsAPIPort) *RefreshPlaceDetailsService {
+	return &RefreshPlaceDetailsService{placesRepo: placesRepo, detailsAPI: detailsAPI}
+}
+
+// RefreshMostViewed refreshes the limit most viewed places
+func (s *RefreshPlaceDetailsService) RefreshMostViewed(ctx context.Context, limit int) (int, error) {
+	placeIDs, err := s.placesRepo.GetMostViewedPlaceIDs(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	return s.RefreshPlaceDetails(ctx, placeIDs)
+}
+
+// RefreshPlaceDetails refreshes the given places and returns how many were updated.
+// A place that fails is logged and skipped; running out of budget or a rejected key stops the refresh.
+func (s *RefreshPlaceDetailsService) RefreshPlaceDetails(ctx context.Context, placeIDs []string) (int, error) {
+	fieldMask := s.detailsAPI.FieldMask()
+	log.Printf("Refreshing %d places with the %s field mask", len(placeIDs), fieldMask.Name)
+
+	refreshed := 0
+	for _, placeID := range placeIDs {
+		if err := ctx.Err(); err != nil {
+			return refreshed, err
+		}
+
+		place, err := s.detailsAPI.FetchPlaceDetails(ctx, placeID)
+		if isStopError(err) {
+			return refreshed, err
+		}
+		if err != nil {
+			log.Printf("Failed to fetch details of place %s: %v", placeID, err)
+			continue
+		}
+
+		if place.ID == "" {
+			place.ID = placeID
+		}
+		toRow(place, fieldMask)
+		if err := s.placesRepo.UpsertPlaceDetails(ctx, *place); err != nil {
+			return refreshed, fmt.Errorf("failed to save place %s: %w", placeID, err)
+		}
+		refreshed++
+		log.Printf("Refreshed %s (%s), status %s", place.Name, placeID, place.BusinessStatus)
+	}
+
+	log.Printf("Refreshed %d/%d places", refreshed, len(placeIDs))
+	return refreshed, nil
+}
+
+// toRow fills the columns the ETL derives from a raw place
+func toRow(place *domain.Place, fieldMask domain.FieldMask) {
+	if place.DisplayName != nil {
+		place.Name = place.DisplayName.Text
+	}
+	if place.Location != nil {
+		place.Lat = place.Location.Lat
+		place.Lng = place.Location.Lng
+	}
+	now := time.Now()
+	place.FetchedAt = &now
+	place.FieldLevel = fieldMask.Level
+	place.Provider = domain.ProviderGoogle
+}
@@ -0,0 +1,118 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+// RefreshPlacesService re-queries circles crawled earlier so ratings, opening hours
+// and closures stay current without crawling whole areas again
+type RefreshPlacesService struct {
+	placesRepo     port.SearchResultsRepository
+	categoriesRepo port.CategoriesRepository
+	apiAdapter     port.PlacesAPIPort
+}
+
+func NewRefreshPlacesService(placesRepo port.SearchResultsRepository, categoriesRepo port.CategoriesRepository, apiAdapter port.PlacesAPIPort) *RefreshPlacesService {
+	return &RefreshPlacesService{
+		placesRepo:     placesRepo,
+		categoriesRepo: categoriesRepo,
+		apiAdapter:     apiAdapter,
+	}
+}
+
+// RefreshPlaces fetches again up to limit circles of the category (every category if empty)
+// that were crawled with the adapter's field mask and last fetched before olderThan, oldest first.
+// Only the stored circles are re-queried: a circle that now hits the result cap is reported
+// as saturated rather than subdivided, run-fetch-places covers it in full.
+func (s *RefreshPlacesService) RefreshPlaces(ctx context.Context, category string, olderThan time.Time, limit int) (*domain.RefreshReport, error) {
+	circles, err := s.placesRepo.GetStaleCircles(ctx, category, s.apiAdapter.FieldMask(), olderThan, limit)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Refreshing %d circles fetched before %s", len(circles), olderThan.Format(time.RFC3339))
+
+	report := &domain.RefreshReport{Circles: len(circles)}
+	categoryTypes := make(map[string][]string)
+	var stopErr error
+	for _, circle := range circles {
+		types, ok := categoryTypes[circle.Category]
+		if !ok {
+			types, err = s.categoriesRepo.GetCategoryTypes(ctx, circle.Category)
+			if err != nil {
+				return report, fmt.Errorf("failed to get types for category %s: %w", circle.Category, err)
+			}
+			categoryTypes[circle.Category] = types
+		}
+
+		err := s.refreshCircle(ctx, circle, types, report)
+		if ctx.Err() != nil {
+			stopErr = ctx.Err()
+			break
+		}
+		if isStopError(err) {
+			stopErr = err
+			break
+		}
+		if err != nil {
+			report.Failed++
+			log.Printf("Failed to refresh %s at (%.6f, %.6f, %.2fm): %v", circle.Category, circle.Circle.Lat, circle.Circle.Lng, circle.Circle.Radius, err)
+		}
+	}
+
+	log.Printf("Refreshed %d/%d circles (%d failed): %d places added, %d removed, %d circles saturated",
+		report.Refreshed, report.Circles, report.Failed, report.PlacesAdded, report.PlacesRemoved, report.Saturated)
+
+	// Running out of budget is a clean stop, anything else fails the refresh
+	if stopErr != nil {
+		log.Printf("Stopped refresh: %v", stopErr)
+		report.BudgetExhausted = errors.Is(stopErr, domain.ErrBudgetExhausted)
+		if !report.BudgetExhausted {
+			return report, stopErr
+		}
+	}
+	return report, nil
+}
+
+// refreshCircle fetches one stored circle again and replaces its search results
+func (s *RefreshPlacesService) refreshCircle(ctx context.Context, circle domain.SearchCircle, types []string, report *domain.RefreshReport) error {
+	places, err := s.apiAdapter.FetchPlaces(ctx, domain.RequestParams{Types: types, Circle: circle.Circle})
+	if err != nil {
+		return err
+	}
+	if err := s.placesRepo.UpdateSearchResults(ctx, circle.ID, places); err != nil {
+		return err
+	}
+	report.Refreshed++
+
+	previous := make(map[string]bool, len(circle.PlaceIDs))
+	for _, id := range circle.PlaceIDs {
+		previous[id] = true
+	}
+	current := make(map[string]bool, len(places))
+	for _, place := range places {
+		id := place.Place.ID
+		current[id] = true
+		if !previous[id] {
+			report.PlacesAdded++
+		}
+	}
+	for id := range previous {
+		if !current[id] {
+			report.PlacesRemoved++
+		}
+	}
+
+	if len(places) >= domain.MaxResultsPerReq && len(circle.PlaceIDs) < domain.MaxResultsPerReq {
+		report.Saturated++
+		log.Printf("Circle %s at (%.6f, %.6f, %.2fm) now has %d+ places, crawl it again with run-fetch-places to pick up the rest",
+			circle.Category, circle.Circle.Lat, circle.Circle.Lng, circle.Circle.Radius, len(places))
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// osmAdminLevels maps OpenStreetMap admin_level values to area levels, as used in Vietnam
+var osmAdminLevels = map[string]string{
+	"4": domain.AreaLevelCity,
+	"6": domain.AreaLevelDistrict,
+	"8": domain.AreaLevelWard,
+}
+
+// LoadAreas reads the cities, districts and wards of a GeoJSON file, see ParseAreas
+func LoadAreas(path string) (areas []domain.Area, skipped int, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read areas file: %w", err)
+	}
+	return ParseAreas(data, filepath.Base(path))
+}
+
+// ParseAreas reads every Polygon or MultiPolygon feature with a name and a level as an area.
+// The level is read from a level property (city, district or ward) or an OpenStreetMap
+// admin_level (4, 6 or 8). The ID is the feature's place_id, id or @id, or else source
+// and the feature's position. Features without a polygon, name or level are skipped.
+func ParseAreas(data []byte, source string) (areas []domain.Area, skipped int, err error) {
+	var obj object
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, 0, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	features := obj.Features
+	if obj.Type == "Feature" {
+		features = []object{obj}
+	}
+
+	for i, feature := range features {
+		if feature.Geometry == nil {
+			skipped++
+			continue
+		}
+		boundary, err := polygons(*feature.Geometry)
+		if err != nil {
+			return nil, 0, fmt.Errorf("feature %d: %w", i, err)
+		}
+		name := property(feature.Properties, "name")
+		level := property(feature.Properties, "level")
+		if adminLevel := property(feature.Properties, "admin_level"); level == "" && adminLevel != "" {
+			level = osmAdminLevels[adminLevel]
+		}
+		if len(boundary) == 0 || name == "" || level == "" {
+			skipped++
+			continue
+		}
+
+		id := property(feature.Properties, "place_id")
+		if id == "" {
+			id = toString(feature.ID)
+		}
+		if id == "" {
+			id = property(feature.Properties, "@id")
+		}
+		if id == "" {
+			id = fmt.Sprintf("%s#%d", source, i)
+		}
+		areas = append(areas, domain.Area{PlaceID: id, Name: name, Level: level, Boundary: boundary})
+	}
+
+	if len(areas) == 0 {
+		return nil, skipped, fmt.Errorf("GeoJSON %s has no features with a polygon, a name and a level", obj.Type)
+	}
+	return areas, skipped, nil
+}
+
+// property returns a string or numeric property as a string, empty if it's missing
+func property(properties map[string]interface{}, key string) string {
+	return toString(properties[key])
+}
+
+func toString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
\ No newline at end of file
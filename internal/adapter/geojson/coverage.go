@@ -0,0 +1,53 @@
+package geojson
+
+import (
+	"time"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// Coverage map colors, set as simplestyle properties that most GeoJSON viewers read
+const (
+	completeColor  = "#1a9850" // The search returned fewer places than the cap
+	saturatedColor = "#d73027" // The search hit the cap, the circle may hold more places
+	areaColor      = "#000000"
+)
+
+// CoverageCollection draws the search circles of a coverage report colored by their
+// result count, followed by the outline of each area with its coverage
+func CoverageCollection(report domain.CoverageReport) FeatureCollection {
+	features := make([]Feature, 0, len(report.Circles)+len(report.Areas))
+	for _, c := range report.Circles {
+		color := completeColor
+		if c.Saturated() {
+			color = saturatedColor
+		}
+		properties := map[string]interface{}{
+			"category":     c.Category,
+			"radius":       c.Circle.Radius,
+			"num_places":   len(c.PlaceIDs),
+			"saturated":    c.Saturated(),
+			"stroke":       color,
+			"fill":         color,
+			"fill-opacity": 0.2,
+		}
+		if !c.FetchedAt.IsZero() {
+			properties["fetched_at"] = c.FetchedAt.Format(time.RFC3339)
+		}
+		features = append(features, CircleFeature(c.Circle, properties))
+	}
+
+	for _, a := range report.Areas {
+		features = append(features, BoundaryFeature(a.Area.Outline(), map[string]interface{}{
+			"area":              a.Name,
+			"category":          report.Category,
+			"circles":           a.Circles,
+			"covered_percent":   a.CoveredPercent,
+			"saturated_percent": a.SaturatedPercent,
+			"uncovered_percent": a.UncoveredPercent,
+			"stroke":            areaColor,
+			"fill-opacity":      0,
+		}))
+	}
+	return NewFeatureCollection(features)
+}
\ No newline at end of file
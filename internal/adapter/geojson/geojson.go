@@ -0,0 +1,110 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// object is any GeoJSON object; only the members used by boundaries and areas are decoded
+type object struct {
+	Type        string                 `json:"type"`
+	ID          interface{}            `json:"id"`
+	Coordinates json.RawMessage        `json:"coordinates"`
+	Geometry    *object                `json:"geometry"`
+	Properties  map[string]interface{} `json:"properties"`
+	Features    []object               `json:"features"`
+}
+
+// LoadBoundary reads a boundary from a GeoJSON file
+func LoadBoundary(path string) (domain.Boundary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read boundary file: %w", err)
+	}
+	return ParseBoundary(data)
+}
+
+// ParseBoundary reads the polygons of a GeoJSON Polygon, MultiPolygon, Feature or
+// FeatureCollection. Features with other geometry types are ignored.
+func ParseBoundary(data []byte) (domain.Boundary, error) {
+	var obj object
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, fmt.Errorf("invalid GeoJSON: %w", err)
+	}
+	boundary, err := polygons(obj)
+	if err != nil {
+		return nil, err
+	}
+	if len(boundary) == 0 {
+		return nil, fmt.Errorf("GeoJSON %s has no polygons", obj.Type)
+	}
+	return boundary, nil
+}
+
+func polygons(obj object) (domain.Boundary, error) {
+	switch obj.Type {
+	case "FeatureCollection":
+		var boundary domain.Boundary
+		for _, feature := range obj.Features {
+			polygons, err := polygons(feature)
+			if err != nil {
+				return nil, err
+			}
+			boundary = append(boundary, polygons...)
+		}
+		return boundary, nil
+	case "Feature":
+		if obj.Geometry == nil {
+			return nil, nil
+		}
+		return polygons(*obj.Geometry)
+	case "Polygon":
+		var coordinates [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coordinates); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		polygon, err := toPolygon(coordinates)
+		if err != nil {
+			return nil, err
+		}
+		return domain.Boundary{polygon}, nil
+	case "MultiPolygon":
+		var coordinates [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &coordinates); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+		var boundary domain.Boundary
+		for _, c := range coordinates {
+			polygon, err := toPolygon(c)
+			if err != nil {
+				return nil, err
+			}
+			boundary = append(boundary, polygon)
+		}
+		return boundary, nil
+	default:
+		return nil, nil
+	}
+}
+
+// toPolygon converts GeoJSON rings of [lng, lat] positions
+func toPolygon(coordinates [][][]float64) (domain.Polygon, error) {
+	polygon := make(domain.Polygon, 0, len(coordinates))
+	for _, positions := range coordinates {
+		if len(positions) < 4 {
+			return nil, fmt.Errorf("polygon ring has %d positions, need at least 4", len(positions))
+		}
+		ring := make(domain.Ring, 0, len(positions))
+		for _, position := range positions {
+			if len(position) < 2 {
+				return nil, fmt.Errorf("invalid position %v", position)
+			}
+			ring = append(ring, domain.LatLng{Lat: position[1], Lng: position[0]})
+		}
+		polygon = append(polygon, ring)
+	}
+	return polygon, nil
+}
\ No newline at end of file
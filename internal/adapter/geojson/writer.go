@@ -0,0 +1,85 @@
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// circleSegments is the number of sides of the polygon drawn for a circle
+const circleSegments = 32
+
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+func NewFeatureCollection(features []Feature) FeatureCollection {
+	if features == nil {
+		features = []Feature{}
+	}
+	return FeatureCollection{Type: "FeatureCollection", Features: features}
+}
+
+// CircleFeature draws a circle as a polygon, since GeoJSON has no circles
+func CircleFeature(c domain.Circle, properties map[string]interface{}) Feature {
+	ring := make([][]float64, 0, circleSegments+1)
+	for i := 0; i <= circleSegments; i++ {
+		angle := 2 * math.Pi * float64(i%circleSegments) / circleSegments
+		lat := c.Lat + c.Radius*math.Sin(angle)*domain.LatMeterToDegree
+		lng := c.Lng + c.Radius*math.Cos(angle)*domain.LatMeterToDegree/math.Cos(c.Lat*math.Pi/180)
+		ring = append(ring, []float64{lng, lat})
+	}
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "Polygon", Coordinates: [][][]float64{ring}},
+		Properties: properties,
+	}
+}
+
+// BoundaryFeature converts a boundary back to a GeoJSON MultiPolygon
+func BoundaryFeature(boundary domain.Boundary, properties map[string]interface{}) Feature {
+	coordinates := make([][][][]float64, 0, len(boundary))
+	for _, polygon := range boundary {
+		rings := make([][][]float64, 0, len(polygon))
+		for _, ring := range polygon {
+			positions := make([][]float64, 0, len(ring))
+			for _, p := range ring {
+				positions = append(positions, []float64{p.Lng, p.Lat})
+			}
+			rings = append(rings, positions)
+		}
+		coordinates = append(coordinates, rings)
+	}
+	return Feature{
+		Type:       "Feature",
+		Geometry:   Geometry{Type: "MultiPolygon", Coordinates: coordinates},
+		Properties: properties,
+	}
+}
+
+// WriteFile saves the features as an indented GeoJSON file
+func WriteFile(path string, collection FeatureCollection) error {
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize GeoJSON: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write GeoJSON file: %w", err)
+	}
+	return nil
+}
\ No newline at end of file
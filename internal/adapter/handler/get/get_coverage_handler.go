@@ -0,0 +1,38 @@
+package get
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wheretoeat/internal/adapter/geojson"
+	"wheretoeat/internal/core/port"
+)
+
+type GetCoverageHandler struct {
+	service port.GetCoverageServicePort
+}
+
+func NewGetCoverageHandler(service port.GetCoverageServicePort) *GetCoverageHandler {
+	return &GetCoverageHandler{service: service}
+}
+
+// Handle returns the coverage map of a category as a GeoJSON FeatureCollection
+func (h *GetCoverageHandler) Handle(c *gin.Context) {
+	category := c.Query("category")
+	if category == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing category"})
+		return
+	}
+
+	report, err := h.service.GetCoverage(c.Request.Context(), category, c.Query("area"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Area not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, geojson.CoverageCollection(*report))
+}
\ No newline at end of file
@@ -0,0 +1,36 @@
+package get
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wheretoeat/internal/core/port"
+)
+
+type GetPlaceHandler struct {
+	service port.GetPlaceServicePort
+}
+
+func NewGetPlaceHandler(service port.GetPlaceServicePort) *GetPlaceHandler {
+	return &GetPlaceHandler{service: service}
+}
+
+func (h *GetPlaceHandler) Handle(c *gin.Context) {
+	placeID := c.Param("id")
+	if placeID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid place id"})
+		return
+	}
+
+	place, err := h.service.GetPlace(c.Request.Context(), placeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if place == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Place not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, place)
+}
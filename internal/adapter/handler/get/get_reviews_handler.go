@@ -0,0 +1,36 @@
+package get
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"wheretoeat/internal/core/port"
+)
+
+type GetReviewsHandler struct {
+	service port.GetReviewsServicePort
+}
+
+func NewGetReviewsHandler(service port.GetReviewsServicePort) *GetReviewsHandler {
+	return &GetReviewsHandler{service: service}
+}
+
+func (h *GetReviewsHandler) Handle(c *gin.Context) {
+	placeID := c.Param("id")
+	if placeID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid place id"})
+		return
+	}
+
+	reviews, err := h.service.GetReviews(c.Request.Context(), placeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if reviews == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Place not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reviews)
+}
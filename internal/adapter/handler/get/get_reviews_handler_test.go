@@ -0,0 +1,54 @@
+package get
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"wheretoeat/internal/core/domain"
+)
+
+// stubReviewsService knows place "reviewed" with one review and place "quiet" with none
+type stubReviewsService struct{}
+
+func (stubReviewsService) GetReviews(ctx context.Context, placeID string) ([]domain.Review, error) {
+	switch placeID {
+	case "reviewed":
+		return []domain.Review{{Name: "places/reviewed/reviews/1", Rating: 5}}, nil
+	case "quiet":
+		return []domain.Review{}, nil
+	}
+	return nil, nil
+}
+
+func TestGetReviews(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		placeID    string
+		wantStatus int
+		wantBody   string
+	}{
+		{"reviewed", http.StatusOK, ""},
+		{"quiet", http.StatusOK, "[]"},
+		{"unknown", http.StatusNotFound, `{"error":"Place not found"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.placeID, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/places/:id/reviews", NewGetReviewsHandler(stubReviewsService{}).Handle)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/places/"+tt.placeID+"/reviews", nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("got body %s, want %s", w.Body, tt.wantBody)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,65 @@
+package get
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"wheretoeat/internal/core/port"
+)
+
+const (
+	defaultSimilarRadius = 3000.0  // default distance cap in meters
+	maxSimilarRadius     = 20000.0 // largest distance cap accepted
+	defaultSimilarLimit  = 10
+	maxSimilarLimit      = 50
+)
+
+type GetSimilarPlacesHandler struct {
+	service port.GetSimilarPlacesServicePort
+}
+
+func NewGetSimilarPlacesHandler(service port.GetSimilarPlacesServicePort) *GetSimilarPlacesHandler {
+	return &GetSimilarPlacesHandler{service: service}
+}
+
+func (h *GetSimilarPlacesHandler) Handle(c *gin.Context) {
+	placeID := c.Param("id")
+	if placeID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid place id"})
+		return
+	}
+
+	// if radius missing, fall back to the default distance cap
+	radius, err := strconv.ParseFloat(c.Query("radius"), 64)
+	if err != nil {
+		radius = defaultSimilarRadius
+	}
+	// ParseFloat accepts "NaN" and "Inf", which no range check catches
+	if math.IsNaN(radius) || math.IsInf(radius, 0) || radius <= 0 || radius > maxSimilarRadius {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid radius"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil {
+		limit = defaultSimilarLimit
+	}
+	if limit <= 0 || limit > maxSimilarLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	places, err := h.service.GetSimilarPlaces(c.Request.Context(), placeID, radius, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if places == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Place not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, places)
+}
@@ -0,0 +1,57 @@
+package get
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"wheretoeat/internal/core/domain"
+)
+
+type stubSimilarPlacesService struct {
+	radius float64
+}
+
+func (s *stubSimilarPlacesService) GetSimilarPlaces(ctx context.Context, placeID string, maxDistance float64, limit int) ([]domain.SimilarPlace, error) {
+	s.radius = maxDistance
+	return []domain.SimilarPlace{}, nil
+}
+
+func TestGetSimilarPlacesRadius(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantRadius float64
+	}{
+		{"", http.StatusOK, defaultSimilarRadius},
+		{"?radius=500", http.StatusOK, 500},
+		{"?radius=abc", http.StatusOK, defaultSimilarRadius},
+		{"?radius=0", http.StatusBadRequest, 0},
+		{"?radius=-10", http.StatusBadRequest, 0},
+		{"?radius=50000", http.StatusBadRequest, 0},
+		{"?radius=NaN", http.StatusBadRequest, 0},
+		{"?radius=Inf", http.StatusBadRequest, 0},
+		{"?radius=-Inf", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			service := &stubSimilarPlacesService{}
+			router := gin.New()
+			router.GET("/places/:id/similar", NewGetSimilarPlacesHandler(service).Handle)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/places/abc/similar"+tt.query, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("got status %d, want %d", w.Code, tt.wantStatus)
+			}
+			if service.radius != tt.wantRadius {
+				t.Errorf("service got radius %g, want %g", service.radius, tt.wantRadius)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -1,66 +1,114 @@
--- Enable PostGIS for geospatial queries
-CREATE EXTENSION IF NOT EXISTS postgis;
-
--- Places table (flattened with category, lat, lng, radius from raw response)
-CREATE TABLE places (
-    place_id VARCHAR(255) PRIMARY KEY,
-    name TEXT NOT NULL,
-    category VARCHAR(255),
-    lat DOUBLE PRECISION, 
-    lng DOUBLE PRECISION, 
-    radius DOUBLE PRECISION, 
-    user_rating_count INT,
-    rating DOUBLE PRECISION,
-    icon_mask_base_uri TEXT,
-    primary_type VARCHAR(255),
-    short_address TEXT,
-    phone_number TEXT,
-    international_phone TEXT,
-    takeout BOOLEAN,
-    good_for_groups BOOLEAN,
-    google_maps_uri TEXT,
-    utc_offset_minutes INT,
-    icon_background_color TEXT,
-    live_music BOOLEAN,
-    restroom BOOLEAN,
-    dine_in BOOLEAN,
-    serves_breakfast BOOLEAN,
-    formatted_address TEXT,
-    location GEOMETRY(POINT, 4326) -- PostGIS point for lat/lng
-);
-
--- Photos table
-CREATE TABLE photos (
-    photo_id SERIAL PRIMARY KEY,
-    place_id VARCHAR(255) REFERENCES places(place_id),
-    flag_content_uri TEXT,
-    img_url TEXT
-);
-
--- Reviews table
-CREATE TABLE reviews (
-    review_id SERIAL PRIMARY KEY,
-    place_id VARCHAR(255) REFERENCES places(place_id),
-    text TEXT,
-    rating INT,
-    author TEXT -- Add more fields as needed
-);
-
--- Opening Hours table
-CREATE TABLE opening_hours (
-    opening_hours_id SERIAL PRIMARY KEY,
-    place_id VARCHAR(255) REFERENCES places(place_id),
-    type VARCHAR(50), -- 'regular' or 'current'
-    periods TEXT -- JSON or text representation of periods
-);
-
--- Place Types table (many-to-many)
-CREATE TABLE place_types (
-    place_id VARCHAR(255) REFERENCES places(place_id),
-    type VARCHAR(255),
-    PRIMARY KEY (place_id, type)
-);
-
--- Indexes for performance
-CREATE INDEX places_location_idx ON places USING GIST (location);
-CREATE INDEX places_category_idx ON places (category);
\ No newline at end of file
+-- Enable PostGIS for geospatial queries
+CREATE EXTENSION IF NOT EXISTS postgis;
+
+-- Places table (flattened with lat, lng, radius from raw response)
+CREATE TABLE places (
+    place_id VARCHAR(255) PRIMARY KEY,
+    name TEXT NOT NULL,
+    lat DOUBLE PRECISION, 
+    lng DOUBLE PRECISION, 
+    radius DOUBLE PRECISION, 
+    user_rating_count INT,
+    rating DOUBLE PRECISION,
+    price_level VARCHAR(50), -- Google price level enum, e.g. PRICE_LEVEL_MODERATE
+    icon_mask_base_uri TEXT,
+    primary_type VARCHAR(255),
+    short_address TEXT,
+    phone_number TEXT,
+    international_phone TEXT,
+    takeout BOOLEAN,
+    good_for_groups BOOLEAN,
+    google_maps_uri TEXT,
+    utc_offset_minutes INT,
+    icon_background_color TEXT,
+    live_music BOOLEAN,
+    restroom BOOLEAN,
+    dine_in BOOLEAN,
+    serves_breakfast BOOLEAN,
+    formatted_address TEXT,
+    provider VARCHAR(20) NOT NULL DEFAULT 'google', -- 'google' or 'osm'
+    field_level SMALLINT NOT NULL DEFAULT 3, -- Richest field mask the data came from: 0 basic, 1 contact, 2 atmosphere, 3 full
+    business_status VARCHAR(50), -- Google business status, e.g. CLOSED_PERMANENTLY
+    fetched_at TIMESTAMPTZ, -- When the place data was last fetched, NULL for results saved before fetch times were recorded
+    city TEXT, -- Administrative areas the place lies in, assigned by the ETL from imported boundaries
+    district TEXT,
+    ward TEXT,
+    location GEOMETRY(POINT, 4326) -- PostGIS point for lat/lng
+);
+
+-- Place Categories table (many-to-many, a place can be crawled under several categories)
+CREATE TABLE place_categories (
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    category VARCHAR(255),
+    PRIMARY KEY (place_id, category)
+);
+
+-- Photos table
+CREATE TABLE photos (
+    photo_id SERIAL PRIMARY KEY,
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    flag_content_uri TEXT,
+    img_url TEXT
+);
+
+-- Reviews table
+CREATE TABLE reviews (
+    review_id SERIAL PRIMARY KEY,
+    name TEXT UNIQUE NOT NULL, -- Google review resource name (places/{place}/reviews/{review})
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    text TEXT,
+    language_code VARCHAR(20),
+    rating DOUBLE PRECISION,
+    author TEXT,
+    publish_time TIMESTAMPTZ,
+    relative_publish_time_description TEXT
+);
+
+-- Review summaries table (output of the offline review analysis job)
+CREATE TABLE place_review_summaries (
+    place_id VARCHAR(255) PRIMARY KEY REFERENCES places(place_id),
+    review_count INT,
+    aspects JSONB, -- Per-aspect mention and sentiment counts
+    top_phrases JSONB,
+    highlights JSONB, -- e.g. ["cheap", "slow service"]
+    analyzed_at TIMESTAMPTZ
+);
+
+-- Place Dishes table (dishes mentioned in the place name or its reviews)
+CREATE TABLE place_dishes (
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    dish VARCHAR(255), -- Canonical name from the dish dictionary
+    mention_count INT,
+    in_name BOOLEAN,
+    PRIMARY KEY (place_id, dish)
+);
+
+-- Opening Hours table
+CREATE TABLE opening_hours (
+    opening_hours_id SERIAL PRIMARY KEY,
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    type VARCHAR(50), -- 'regular' or 'current'
+    periods TEXT -- JSON or text representation of periods
+);
+
+-- Place Types table (many-to-many)
+CREATE TABLE place_types (
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    type VARCHAR(255),
+    PRIMARY KEY (place_id, type)
+);
+
+-- Place Views table (detail page views, used to pick places to refresh)
+CREATE TABLE place_views (
+    place_id VARCHAR(255) PRIMARY KEY REFERENCES places(place_id),
+    view_count BIGINT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMPTZ
+);
+
+-- Indexes for performance
+CREATE INDEX places_location_idx ON places USING GIST (location);
+CREATE INDEX places_district_idx ON places (lower(district));
+CREATE INDEX place_categories_category_idx ON place_categories (category);
+CREATE INDEX reviews_place_id_idx ON reviews (place_id);
+CREATE INDEX place_dishes_dish_idx ON place_dishes (dish);
+CREATE INDEX place_types_type_idx ON place_types (type);
\ No newline at end of file
@@ -0,0 +1,87 @@
+-- Upgrades a database created from an earlier places.sql to the current schema.
+-- New databases only need places.sql. Every step checks what is already there,
+-- so the script can be run again, e.g. after a failed run.
+BEGIN;
+
+-- Reviews keyed by their Google resource name, with the fields the ETL loads.
+-- Rows stored before they had a name can't be matched to a review again and are
+-- dropped; the next ETL run loads them back.
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS name TEXT;
+DELETE FROM reviews WHERE name IS NULL;
+ALTER TABLE reviews ALTER COLUMN name SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS reviews_name_key ON reviews (name);
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS language_code VARCHAR(20);
+ALTER TABLE reviews ALTER COLUMN rating TYPE DOUBLE PRECISION;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS publish_time TIMESTAMPTZ;
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS relative_publish_time_description TEXT;
+CREATE INDEX IF NOT EXISTS reviews_place_id_idx ON reviews (place_id);
+
+-- Review summaries
+CREATE TABLE IF NOT EXISTS place_review_summaries (
+    place_id VARCHAR(255) PRIMARY KEY REFERENCES places(place_id),
+    review_count INT,
+    aspects JSONB,
+    top_phrases JSONB,
+    highlights JSONB,
+    analyzed_at TIMESTAMPTZ
+);
+
+-- Place dishes
+CREATE TABLE IF NOT EXISTS place_dishes (
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    dish VARCHAR(255),
+    mention_count INT,
+    in_name BOOLEAN,
+    PRIMARY KEY (place_id, dish)
+);
+CREATE INDEX IF NOT EXISTS place_dishes_dish_idx ON place_dishes (dish);
+
+-- Price level and place types, used by similar places
+ALTER TABLE places ADD COLUMN IF NOT EXISTS price_level VARCHAR(50);
+CREATE INDEX IF NOT EXISTS place_types_type_idx ON place_types (type);
+
+-- Place categories, moved out of places.category. A place keeps the one category
+-- it had; the next ETL run adds every other category it was crawled under.
+CREATE TABLE IF NOT EXISTS place_categories (
+    place_id VARCHAR(255) REFERENCES places(place_id),
+    category VARCHAR(255),
+    PRIMARY KEY (place_id, category)
+);
+CREATE INDEX IF NOT EXISTS place_categories_category_idx ON place_categories (category);
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'places' AND column_name = 'category'
+    ) THEN
+        INSERT INTO place_categories (place_id, category)
+        SELECT place_id, category FROM places WHERE category IS NOT NULL
+        ON CONFLICT DO NOTHING;
+        ALTER TABLE places DROP COLUMN category; -- Drops places_category_idx too
+    END IF;
+END $$;
+
+-- Field mask level. Places loaded before field masks were fetched with every field.
+ALTER TABLE places ADD COLUMN IF NOT EXISTS field_level SMALLINT NOT NULL DEFAULT 3;
+
+-- Data provider. Places loaded before OpenStreetMap imports all come from Google.
+ALTER TABLE places ADD COLUMN IF NOT EXISTS provider VARCHAR(20) NOT NULL DEFAULT 'google';
+
+-- Freshness, filled in by the next ETL run
+ALTER TABLE places ADD COLUMN IF NOT EXISTS business_status VARCHAR(50);
+ALTER TABLE places ADD COLUMN IF NOT EXISTS fetched_at TIMESTAMPTZ;
+
+-- Place views
+CREATE TABLE IF NOT EXISTS place_views (
+    place_id VARCHAR(255) PRIMARY KEY REFERENCES places(place_id),
+    view_count BIGINT NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMPTZ
+);
+
+-- Administrative areas, assigned by the next ETL run once boundaries are imported
+ALTER TABLE places ADD COLUMN IF NOT EXISTS city TEXT;
+ALTER TABLE places ADD COLUMN IF NOT EXISTS district TEXT;
+ALTER TABLE places ADD COLUMN IF NOT EXISTS ward TEXT;
+CREATE INDEX IF NOT EXISTS places_district_idx ON places (lower(district));
+
+COMMIT;
\ No newline at end of file
@@ -0,0 +1,210 @@
+package osm
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+const importChunkSize = 500 // Places per raw document
+
+// ImportOSMService loads eating and drinking places from an OpenStreetMap extract
+// into the search results store, next to the Places API responses
+type ImportOSMService struct {
+	placesRepo     port.SearchResultsRepository
+	categoriesRepo port.CategoriesRepository
+}
+
+func NewImportOSMService(placesRepo port.SearchResultsRepository, categoriesRepo port.CategoriesRepository) *ImportOSMService {
+	return &ImportOSMService{
+		placesRepo:     placesRepo,
+		categoriesRepo: categoriesRepo,
+	}
+}
+
+// ImportOSM reads an OSM PBF (.pbf) or Overpass JSON (.json) extract. Places are assigned to
+// every configured category sharing one of their types; places matching no category go to
+// fallbackCategory, or are skipped if it's empty. Importing the same file again replaces it.
+func (s *ImportOSMService) ImportOSM(ctx context.Context, path string, fallbackCategory string) error {
+	typeCategories, err := s.typeCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	// First pass: the places themselves, and the nodes their ways are made of
+	var places []element
+	neededNodes := make(map[int64]bool)
+	err = readExtract(path, func(e element) {
+		if name(e.Tags) == "" || placeTypes(e.Tags) == nil {
+			return
+		}
+		places = append(places, e)
+		for _, ref := range e.NodeRefs {
+			neededNodes[ref] = true
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// Second pass: locate ways, e.g. restaurants mapped as buildings, at the center of their nodes
+	if len(neededNodes) > 0 {
+		nodes := make(map[int64]domain.LatLng, len(neededNodes))
+		err = readExtract(path, func(e element) {
+			if e.Type == nodeType && neededNodes[e.ID] {
+				nodes[e.ID] = domain.LatLng{Lat: e.Lat, Lng: e.Lng}
+			}
+		})
+		if err != nil {
+			return err
+		}
+		for i := range places {
+			if len(places[i].NodeRefs) > 0 {
+				places[i].Lat, places[i].Lng = center(places[i].NodeRefs, nodes)
+			}
+		}
+	}
+
+	byCategory := make(map[string][]interface{})
+	skipped := 0
+	for _, e := range places {
+		if e.Lat == 0 && e.Lng == 0 {
+			skipped++ // A way whose nodes aren't in the extract
+			continue
+		}
+		types := placeTypes(e.Tags)
+		categories := categoriesOf(types, typeCategories)
+		if len(categories) == 0 && fallbackCategory != "" {
+			categories = []string{fallbackCategory}
+		}
+		if len(categories) == 0 {
+			skipped++
+			continue
+		}
+		for _, category := range categories {
+			byCategory[category] = append(byCategory[category], toRawPlace(e, types))
+		}
+	}
+
+	source := filepath.Base(path)
+	if err := s.placesRepo.DeleteImportedPlaces(ctx, domain.ProviderOSM, source); err != nil {
+		return err
+	}
+	imported := 0
+	for category, raw := range byCategory {
+		for start := 0; start < len(raw); start += importChunkSize {
+			end := start + importChunkSize
+			if end > len(raw) {
+				end = len(raw)
+			}
+			if err := s.placesRepo.SaveImportedPlaces(ctx, domain.ProviderOSM, source, category, raw[start:end]); err != nil {
+				return err
+			}
+		}
+		log.Printf("Imported %d OSM places for %s", len(raw), category)
+		imported += len(raw)
+	}
+	log.Printf("Imported %d OSM places from %s, skipped %d without a category or location", imported, source, skipped)
+	return nil
+}
+
+// typeCategories maps every configured Places API type to the categories that include it
+func (s *ImportOSMService) typeCategories(ctx context.Context) (map[string][]string, error) {
+	categories, err := s.categoriesRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	typeCategories := make(map[string][]string)
+	for _, category := range categories {
+		types, err := s.categoriesRepo.GetCategoryTypes(ctx, category)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range types {
+			typeCategories[t] = append(typeCategories[t], category)
+		}
+	}
+	return typeCategories, nil
+}
+
+func categoriesOf(types []string, typeCategories map[string][]string) []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, t := range types {
+		for _, category := range typeCategories[t] {
+			if !seen[category] {
+				seen[category] = true
+				categories = append(categories, category)
+			}
+		}
+	}
+	return categories
+}
+
+// toRawPlace shapes an OSM element like a Places API place, so the ETL reads both the same way
+func toRawPlace(e element, types []string) map[string]interface{} {
+	place := map[string]interface{}{
+		"id":          fmt.Sprintf("osm/%s/%d", e.Type, e.ID),
+		"provider":    domain.ProviderOSM,
+		"displayName": map[string]interface{}{"text": name(e.Tags)},
+		"location":    map[string]interface{}{"latitude": e.Lat, "longitude": e.Lng},
+		"types":       types,
+		"primaryType": primaryType(types),
+	}
+	if address := formattedAddress(e.Tags); address != "" {
+		place["formattedAddress"] = address
+	}
+	if phone := phone(e.Tags); phone != "" {
+		place["nationalPhoneNumber"] = phone
+	}
+	return place
+}
+
+// center averages the located nodes of a way, counting the closing node of an area once
+func center(refs []int64, nodes map[int64]domain.LatLng) (lat, lng float64) {
+	if len(refs) > 1 && refs[0] == refs[len(refs)-1] {
+		refs = refs[:len(refs)-1]
+	}
+	n := 0
+	for _, ref := range refs {
+		if node, ok := nodes[ref]; ok {
+			lat += node.Lat
+			lng += node.Lng
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return lat / float64(n), lng / float64(n)
+}
+
+func readExtract(path string, handle func(element)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open OSM extract: %w", err)
+	}
+	defer file.Close()
+
+	var read func(io.Reader, func(element)) error
+	switch {
+	case strings.HasSuffix(path, ".pbf"):
+		read = readPBF
+	case strings.HasSuffix(path, ".json"):
+		read = readOverpassJSON
+	default:
+		return fmt.Errorf("unsupported OSM extract %s, expected a .pbf or Overpass .json file", path)
+	}
+	if err := read(bufio.NewReader(file), handle); err != nil {
+		return fmt.Errorf("failed to read OSM extract: %w", err)
+	}
+	return nil
+}
\ No newline at end of file
@@ -0,0 +1,59 @@
+package osm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	nodeType = "node"
+	wayType  = "way"
+)
+
+// element is an OSM node or way. Ways only have coordinates once resolved from their nodes.
+type element struct {
+	Type     string
+	ID       int64
+	Lat      float64
+	Lng      float64
+	Tags     map[string]string
+	NodeRefs []int64
+}
+
+// overpassResponse is the JSON output of an Overpass API query, e.g. `[out:json]; nwr[amenity=restaurant](area); out center;`
+type overpassResponse struct {
+	Elements []struct {
+		Type   string  `json:"type"`
+		ID     int64   `json:"id"`
+		Lat    float64 `json:"lat"`
+		Lon    float64 `json:"lon"`
+		Center *struct {
+			Lat float64 `json:"lat"`
+			Lon float64 `json:"lon"`
+		} `json:"center"`
+		Nodes []int64           `json:"nodes"`
+		Tags  map[string]string `json:"tags"`
+	} `json:"elements"`
+}
+
+// readOverpassJSON calls handle for every node and way of the response. Ways get the
+// center Overpass computed (out center), or are left for their nodes to be resolved.
+func readOverpassJSON(r io.Reader, handle func(element)) error {
+	var response overpassResponse
+	if err := json.NewDecoder(r).Decode(&response); err != nil {
+		return fmt.Errorf("invalid Overpass JSON: %w", err)
+	}
+	for _, e := range response.Elements {
+		if e.Type != nodeType && e.Type != wayType {
+			continue
+		}
+		el := element{Type: e.Type, ID: e.ID, Lat: e.Lat, Lng: e.Lon, Tags: e.Tags, NodeRefs: e.Nodes}
+		if e.Center != nil {
+			el.Lat, el.Lng = e.Center.Lat, e.Center.Lon
+			el.NodeRefs = nil
+		}
+		handle(el)
+	}
+	return nil
+}
\ No newline at end of file
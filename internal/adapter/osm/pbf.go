@@ -0,0 +1,515 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Minimal reader for the OSM PBF format (https://wiki.openstreetmap.org/wiki/PBF_Format).
+// It decodes nodes, dense nodes and ways with their tags; relations and metadata are skipped.
+
+const (
+	maxBlobHeaderSize = 64 * 1024
+	maxBlobSize       = 32 * 1024 * 1024
+)
+
+// readPBF calls handle for every node and way of the file, in file order
+func readPBF(r io.Reader, handle func(element)) error {
+	for {
+		var headerSize uint32
+		if err := binary.Read(r, binary.BigEndian, &headerSize); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read blob header size: %w", err)
+		}
+		if headerSize > maxBlobHeaderSize {
+			return fmt.Errorf("blob header of %d bytes is too large", headerSize)
+		}
+		headerData := make([]byte, headerSize)
+		if _, err := io.ReadFull(r, headerData); err != nil {
+			return fmt.Errorf("failed to read blob header: %w", err)
+		}
+		blobType, blobSize, err := parseBlobHeader(headerData)
+		if err != nil {
+			return err
+		}
+		if blobSize > maxBlobSize {
+			return fmt.Errorf("blob of %d bytes is too large", blobSize)
+		}
+		blobData := make([]byte, blobSize)
+		if _, err := io.ReadFull(r, blobData); err != nil {
+			return fmt.Errorf("failed to read blob: %w", err)
+		}
+
+		// OSMHeader blobs only describe the file
+		if blobType != "OSMData" {
+			continue
+		}
+		data, err := decompressBlob(blobData)
+		if err != nil {
+			return err
+		}
+		if err := parsePrimitiveBlock(data, handle); err != nil {
+			return err
+		}
+	}
+}
+
+func parseBlobHeader(data []byte) (blobType string, blobSize int, err error) {
+	p := pbReader{buf: data}
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return "", 0, err
+		}
+		switch field {
+		case 1:
+			b, err := p.bytes()
+			if err != nil {
+				return "", 0, err
+			}
+			blobType = string(b)
+		case 3:
+			v, err := p.varint()
+			if err != nil {
+				return "", 0, err
+			}
+			blobSize = int(v)
+		default:
+			if err := p.skip(wireType); err != nil {
+				return "", 0, err
+			}
+		}
+	}
+	return blobType, blobSize, nil
+}
+
+func decompressBlob(data []byte) ([]byte, error) {
+	p := pbReader{buf: data}
+	var raw, zlibData []byte
+	rawSize := 0
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		switch field {
+		case 1:
+			if raw, err = p.bytes(); err != nil {
+				return nil, err
+			}
+		case 2:
+			v, err := p.varint()
+			if err != nil {
+				return nil, err
+			}
+			rawSize = int(v)
+		case 3:
+			if zlibData, err = p.bytes(); err != nil {
+				return nil, err
+			}
+		case 4, 5, 6, 7:
+			return nil, errors.New("unsupported PBF compression, only raw and zlib blobs are supported")
+		default:
+			if err := p.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if raw != nil {
+		return raw, nil
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(zlibData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress blob: %w", err)
+	}
+	defer zr.Close()
+	out := bytes.NewBuffer(make([]byte, 0, rawSize))
+	if _, err := io.Copy(out, zr); err != nil {
+		return nil, fmt.Errorf("failed to decompress blob: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// primitiveBlock holds what's needed to decode the groups of a block
+type primitiveBlock struct {
+	strings     [][]byte
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+func (b *primitiveBlock) coord(offset, value int64) float64 {
+	return 1e-9 * float64(offset+b.granularity*value)
+}
+
+func (b *primitiveBlock) tags(keys, vals []uint64) map[string]string {
+	tags := make(map[string]string, len(keys))
+	for i := range keys {
+		if i < len(vals) && int(keys[i]) < len(b.strings) && int(vals[i]) < len(b.strings) {
+			tags[string(b.strings[keys[i]])] = string(b.strings[vals[i]])
+		}
+	}
+	return tags
+}
+
+func parsePrimitiveBlock(data []byte, handle func(element)) error {
+	block := primitiveBlock{granularity: 100}
+	var groups [][]byte
+
+	// The string table and offsets may come after the groups, so decode groups last
+	p := pbReader{buf: data}
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			table, err := p.bytes()
+			if err != nil {
+				return err
+			}
+			if block.strings, err = parseStringTable(table); err != nil {
+				return err
+			}
+		case 2:
+			group, err := p.bytes()
+			if err != nil {
+				return err
+			}
+			groups = append(groups, group)
+		case 17, 19, 20:
+			v, err := p.varint()
+			if err != nil {
+				return err
+			}
+			switch field {
+			case 17:
+				block.granularity = int64(v)
+			case 19:
+				block.latOffset = int64(v)
+			case 20:
+				block.lonOffset = int64(v)
+			}
+		default:
+			if err := p.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, group := range groups {
+		if err := block.parseGroup(group, handle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseStringTable(data []byte) ([][]byte, error) {
+	p := pbReader{buf: data}
+	var strings [][]byte
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		if field != 1 {
+			if err := p.skip(wireType); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		s, err := p.bytes()
+		if err != nil {
+			return nil, err
+		}
+		strings = append(strings, s)
+	}
+	return strings, nil
+}
+
+func (b *primitiveBlock) parseGroup(data []byte, handle func(element)) error {
+	p := pbReader{buf: data}
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1, 2, 3:
+			msg, err := p.bytes()
+			if err != nil {
+				return err
+			}
+			switch field {
+			case 1:
+				err = b.parseNode(msg, handle)
+			case 2:
+				err = b.parseDenseNodes(msg, handle)
+			case 3:
+				err = b.parseWay(msg, handle)
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			if err := p.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (b *primitiveBlock) parseNode(data []byte, handle func(element)) error {
+	p := pbReader{buf: data}
+	var id, lat, lon int64
+	var keys, vals []uint64
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1, 8, 9:
+			v, err := p.varint()
+			if err != nil {
+				return err
+			}
+			switch field {
+			case 1:
+				id = zigzag(v)
+			case 8:
+				lat = zigzag(v)
+			case 9:
+				lon = zigzag(v)
+			}
+		case 2:
+			if keys, err = p.repeatedVarint(wireType, keys); err != nil {
+				return err
+			}
+		case 3:
+			if vals, err = p.repeatedVarint(wireType, vals); err != nil {
+				return err
+			}
+		default:
+			if err := p.skip(wireType); err != nil {
+				return err
+			}
+		}
+	}
+	handle(element{
+		Type: nodeType,
+		ID:   id,
+		Lat:  b.coord(b.latOffset, lat),
+		Lng:  b.coord(b.lonOffset, lon),
+		Tags: b.tags(keys, vals),
+	})
+	return nil
+}
+
+func (b *primitiveBlock) parseDenseNodes(data []byte, handle func(element)) error {
+	p := pbReader{buf: data}
+	var ids, lats, lons, keysVals []uint64
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			ids, err = p.repeatedVarint(wireType, ids)
+		case 8:
+			lats, err = p.repeatedVarint(wireType, lats)
+		case 9:
+			lons, err = p.repeatedVarint(wireType, lons)
+		case 10:
+			keysVals, err = p.repeatedVarint(wireType, keysVals)
+		default:
+			err = p.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return errors.New("dense nodes have mismatched id and coordinate counts")
+	}
+
+	// Ids and coordinates are delta coded; tags are key/value string indexes
+	// with each node's list ended by a 0
+	var id, lat, lon int64
+	kv := 0
+	for i := range ids {
+		id += zigzag(ids[i])
+		lat += zigzag(lats[i])
+		lon += zigzag(lons[i])
+
+		var tags map[string]string
+		if kv < len(keysVals) {
+			var keys, vals []uint64
+			for kv < len(keysVals) && keysVals[kv] != 0 {
+				if kv+1 < len(keysVals) {
+					keys = append(keys, keysVals[kv])
+					vals = append(vals, keysVals[kv+1])
+				}
+				kv += 2
+			}
+			kv++ // Skip the 0 ending the node's tags
+			tags = b.tags(keys, vals)
+		}
+
+		handle(element{
+			Type: nodeType,
+			ID:   id,
+			Lat:  b.coord(b.latOffset, lat),
+			Lng:  b.coord(b.lonOffset, lon),
+			Tags: tags,
+		})
+	}
+	return nil
+}
+
+func (b *primitiveBlock) parseWay(data []byte, handle func(element)) error {
+	p := pbReader{buf: data}
+	var id int64
+	var keys, vals, refs []uint64
+	for p.more() {
+		field, wireType, err := p.key()
+		if err != nil {
+			return err
+		}
+		switch field {
+		case 1:
+			v, err := p.varint()
+			if err != nil {
+				return err
+			}
+			id = int64(v)
+		case 2:
+			keys, err = p.repeatedVarint(wireType, keys)
+		case 3:
+			vals, err = p.repeatedVarint(wireType, vals)
+		case 8:
+			refs, err = p.repeatedVarint(wireType, refs)
+		default:
+			err = p.skip(wireType)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	nodeRefs := make([]int64, len(refs))
+	var ref int64
+	for i, r := range refs {
+		ref += zigzag(r) // Delta coded
+		nodeRefs[i] = ref
+	}
+	handle(element{
+		Type:     wayType,
+		ID:       id,
+		Tags:     b.tags(keys, vals),
+		NodeRefs: nodeRefs,
+	})
+	return nil
+}
+
+// pbReader decodes the protobuf wire format
+type pbReader struct {
+	buf []byte
+	pos int
+}
+
+var errTruncated = errors.New("truncated protobuf message")
+
+func (p *pbReader) more() bool {
+	return p.pos < len(p.buf)
+}
+
+func (p *pbReader) key() (field int, wireType int, err error) {
+	v, err := p.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(v >> 3), int(v & 7), nil
+}
+
+func (p *pbReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(p.buf[p.pos:])
+	if n <= 0 {
+		return 0, errTruncated
+	}
+	p.pos += n
+	return v, nil
+}
+
+func (p *pbReader) bytes() ([]byte, error) {
+	length, err := p.varint()
+	if err != nil {
+		return nil, err
+	}
+	end := p.pos + int(length)
+	if int(length) < 0 || end > len(p.buf) {
+		return nil, errTruncated
+	}
+	b := p.buf[p.pos:end]
+	p.pos = end
+	return b, nil
+}
+
+// repeatedVarint appends a packed or a single unpacked varint field to values
+func (p *pbReader) repeatedVarint(wireType int, values []uint64) ([]uint64, error) {
+	if wireType == 0 {
+		v, err := p.varint()
+		if err != nil {
+			return nil, err
+		}
+		return append(values, v), nil
+	}
+	packed, err := p.bytes()
+	if err != nil {
+		return nil, err
+	}
+	inner := pbReader{buf: packed}
+	for inner.more() {
+		v, err := inner.varint()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func (p *pbReader) skip(wireType int) error {
+	switch wireType {
+	case 0:
+		_, err := p.varint()
+		return err
+	case 1:
+		p.pos += 8
+	case 2:
+		_, err := p.bytes()
+		return err
+	case 5:
+		p.pos += 4
+	default:
+		return fmt.Errorf("unsupported protobuf wire type %d", wireType)
+	}
+	if p.pos > len(p.buf) {
+		return errTruncated
+	}
+	return nil
+}
+
+func zigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
\ No newline at end of file
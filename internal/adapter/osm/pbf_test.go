@@ -0,0 +1,159 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// pbWriter encodes the few protobuf fields the fixtures need
+type pbWriter struct {
+	buf []byte
+}
+
+func (w *pbWriter) varint(field int, v uint64) *pbWriter {
+	w.buf = binary.AppendUvarint(w.buf, uint64(field<<3))
+	w.buf = binary.AppendUvarint(w.buf, v)
+	return w
+}
+
+func (w *pbWriter) bytes(field int, b []byte) *pbWriter {
+	w.buf = binary.AppendUvarint(w.buf, uint64(field<<3|2))
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+	return w
+}
+
+func (w *pbWriter) packed(field int, values ...uint64) *pbWriter {
+	var packed []byte
+	for _, v := range values {
+		packed = binary.AppendUvarint(packed, v)
+	}
+	return w.bytes(field, packed)
+}
+
+// sint zigzag encodes v like a protobuf sint64
+func sint(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// fileBlock frames a blob of the given type as it appears in a .pbf file
+func fileBlock(blobType string, blob []byte) []byte {
+	header := (&pbWriter{}).bytes(1, []byte(blobType)).varint(3, uint64(len(blob))).buf
+	block := binary.BigEndian.AppendUint32(nil, uint32(len(header)))
+	return append(append(block, header...), blob...)
+}
+
+func rawBlob(data []byte) []byte {
+	return (&pbWriter{}).bytes(1, data).buf
+}
+
+func zlibBlob(t *testing.T, data []byte) []byte {
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return (&pbWriter{}).varint(2, uint64(len(data))).bytes(3, compressed.Bytes()).buf
+}
+
+func stringTable(s ...string) []byte {
+	w := &pbWriter{}
+	for _, str := range s {
+		w.bytes(1, []byte(str))
+	}
+	return w.buf
+}
+
+// testPBF is a small extract: a header blob, a zlib block with a node, dense nodes
+// and a way at the default granularity, and a raw block with its own granularity and offsets
+func testPBF(t *testing.T) []byte {
+	strs := stringTable("", "amenity", "restaurant", "name", "Phở Hòa", "cafe", "Cộng", "building", "yes")
+
+	node := (&pbWriter{}).varint(1, sint(1)).packed(2, 1, 3).packed(3, 2, 4).
+		varint(8, sint(107769000)).varint(9, sint(1067009000)).buf
+	dense := (&pbWriter{}).
+		packed(1, sint(10), sint(1), sint(1)). // ids 10, 11, 12
+		packed(8, sint(107770000), sint(-100), sint(200)).
+		packed(9, sint(1067010000), sint(300), sint(-100)).
+		packed(10, 1, 5, 3, 6, 0, 0, 0).buf // 10 is a named cafe, 11 and 12 have no tags
+	way := (&pbWriter{}).varint(1, 100).packed(2, 1, 7).packed(3, 2, 8).
+		packed(8, sint(10), sint(1), sint(1), sint(-2)).buf // 10, 11, 12 and back to 10
+
+	block := (&pbWriter{}).
+		bytes(2, (&pbWriter{}).bytes(1, node).buf).
+		bytes(2, (&pbWriter{}).bytes(2, dense).bytes(3, way).buf).
+		bytes(1, strs). // The string table may come after the groups
+		buf
+
+	offsetNode := (&pbWriter{}).varint(1, sint(2)).varint(8, sint(5)).varint(9, sint(-5)).buf
+	offsetBlock := (&pbWriter{}).
+		bytes(1, stringTable("")).
+		bytes(2, (&pbWriter{}).bytes(1, offsetNode).buf).
+		varint(17, 1000).varint(19, 21000000000).varint(20, 105800000000).
+		buf
+
+	var file []byte
+	file = append(file, fileBlock("OSMHeader", rawBlob((&pbWriter{}).bytes(4, []byte("OsmSchema-V0.6")).buf))...)
+	file = append(file, fileBlock("OSMData", zlibBlob(t, block))...)
+	file = append(file, fileBlock("OSMData", rawBlob(offsetBlock))...)
+	return file
+}
+
+func TestReadPBF(t *testing.T) {
+	var got []element
+	if err := readPBF(bytes.NewReader(testPBF(t)), func(e element) { got = append(got, e) }); err != nil {
+		t.Fatalf("readPBF: %v", err)
+	}
+
+	want := []element{
+		{Type: nodeType, ID: 1, Lat: 10.7769, Lng: 106.7009, Tags: map[string]string{"amenity": "restaurant", "name": "Phở Hòa"}},
+		{Type: nodeType, ID: 10, Lat: 10.777, Lng: 106.701, Tags: map[string]string{"amenity": "cafe", "name": "Cộng"}},
+		{Type: nodeType, ID: 11, Lat: 10.77699, Lng: 106.70103},
+		{Type: nodeType, ID: 12, Lat: 10.77701, Lng: 106.70102},
+		{Type: wayType, ID: 100, Tags: map[string]string{"amenity": "restaurant", "building": "yes"}, NodeRefs: []int64{10, 11, 12, 10}},
+		{Type: nodeType, ID: 2, Lat: 21.000005, Lng: 105.799995},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d elements, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Type != w.Type || g.ID != w.ID || math.Abs(g.Lat-w.Lat) > 1e-9 || math.Abs(g.Lng-w.Lng) > 1e-9 ||
+			len(g.Tags)+len(w.Tags) > 0 && !reflect.DeepEqual(g.Tags, w.Tags) || !reflect.DeepEqual(g.NodeRefs, w.NodeRefs) {
+			t.Errorf("element %d: got %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestReadPBFErrors(t *testing.T) {
+	file := testPBF(t)
+	lzma := fileBlock("OSMData", (&pbWriter{}).bytes(4, []byte{0}).buf)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"empty file", nil, false},
+		{"truncated header size", file[:2], true},
+		{"truncated blob", file[:len(file)-3], true},
+		{"unsupported compression", lzma, true},
+		{"oversized blob header", binary.BigEndian.AppendUint32(nil, maxBlobHeaderSize+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readPBF(bytes.NewReader(tt.data), func(element) {})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
\ No newline at end of file
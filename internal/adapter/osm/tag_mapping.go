@@ -0,0 +1,98 @@
+package osm
+
+import (
+	"strings"
+)
+
+// amenityTypes maps the imported amenity values onto Places API types
+var amenityTypes = map[string][]string{
+	"restaurant": {"restaurant"},
+	"cafe":       {"cafe"},
+	"fast_food":  {"fast_food_restaurant"},
+	"bar":        {"bar"},
+}
+
+// cuisineTypes maps cuisine values whose Places API type isn't simply "<cuisine>_restaurant"
+var cuisineTypes = map[string][]string{
+	"coffee_shop": {"coffee_shop"},
+	"tea":         {"tea_house"},
+	"bubble_tea":  {"tea_house"},
+	"juice":       {"juice_shop"},
+	"ice_cream":   {"ice_cream_shop"},
+	"dessert":     {"dessert_shop"},
+	"cake":        {"dessert_shop"},
+	"donut":       {"donut_shop"},
+	"bagel":       {"bagel_shop"},
+	"sandwich":    {"sandwich_shop"},
+	"burger":      {"hamburger_restaurant"},
+	"steak_house": {"steak_house"},
+	"bbq":         {"barbecue_restaurant"},
+	"hot_pot":     {"chinese_restaurant"},
+	"noodle":      {"asian_restaurant"},
+	"asian":       {"asian_restaurant"},
+	"wine":        {"wine_bar"},
+	"buffet":      {"buffet_restaurant"},
+}
+
+// placeTypes returns the Places API types matching a place's amenity and cuisine tags.
+// cuisine holds semicolon separated values, e.g. "vietnamese;noodle".
+func placeTypes(tags map[string]string) []string {
+	types, ok := amenityTypes[tags["amenity"]]
+	if !ok {
+		return nil
+	}
+	types = append([]string(nil), types...)
+	for _, cuisine := range strings.Split(tags["cuisine"], ";") {
+		cuisine = strings.ToLower(strings.TrimSpace(cuisine))
+		if cuisine == "" {
+			continue
+		}
+		if mapped, ok := cuisineTypes[cuisine]; ok {
+			types = append(types, mapped...)
+		} else {
+			// Most cuisines have a matching type, e.g. vietnamese_restaurant or pizza_restaurant
+			types = append(types, cuisine+"_restaurant")
+		}
+	}
+	return types
+}
+
+// primaryType picks the most specific type, the first cuisine if there is one
+func primaryType(types []string) string {
+	if len(types) > 1 {
+		return types[1]
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return ""
+}
+
+// formattedAddress joins the addr:* tags, e.g. "12 Lê Lợi, Bến Nghé, Quận 1, Hồ Chí Minh"
+func formattedAddress(tags map[string]string) string {
+	street := strings.TrimSpace(tags["addr:housenumber"] + " " + tags["addr:street"])
+	var parts []string
+	for _, part := range []string{street, tags["addr:subdistrict"], tags["addr:district"], tags["addr:city"]} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// name prefers the local name, falling back to the Vietnamese or English one
+func name(tags map[string]string) string {
+	for _, key := range []string{"name", "name:vi", "name:en"} {
+		if tags[key] != "" {
+			return tags[key]
+		}
+	}
+	return ""
+}
+
+func phone(tags map[string]string) string {
+	if tags["phone"] != "" {
+		return tags["phone"]
+	}
+	return tags["contact:phone"]
+}
\ No newline at end of file
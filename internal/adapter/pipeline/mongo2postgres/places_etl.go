@@ -1,140 +1,320 @@
-package pipeline
-
-import (
-	"context"
-	"log"
-	"encoding/json"
-
-
-	"wheretoeat/internal/adapter/repository/mongodb"
-	"wheretoeat/internal/adapter/repository/postgres"
-	"wheretoeat/internal/core/domain"
-)
-
-const batchSize = 1000 // Adjust based on performance testing
-
-type PlacesETLService struct {
-	mongoRepo *mongodb.PlacesRepo
-	pgRepo    *postgres.PlacesRepo
-}
-
-func NewPlacesETLService(mongoRepo *mongodb.PlacesRepo, pgRepo *postgres.PlacesRepo) *PlacesETLService {
-	return &PlacesETLService{mongoRepo: mongoRepo, pgRepo: pgRepo}
-}
-
-func (s *PlacesETLService) SearchResultsToPostgres(ctx context.Context) error {
-	// Extract raw data from MongoDB
-	rawResponses, err := s.mongoRepo.GetRawPlaces(ctx)
-	if err != nil {
-		return err
-	}
-
-	// Prepare batches
-	var placesBatch []domain.Place
-	var photosBatch []domain.Photo
-	var reviewsBatch []domain.Review
-	var openingHoursBatch []struct {
-		PlaceID string
-		Type    string
-		Periods string
-	}
-	var placeTypesBatch []struct {
-		PlaceID string
-		Type    string
-	}
-
-	for _, raw := range rawResponses {
-		for _, place := range raw.Places {
-			// Enrich place with parent-level fields
-			lat := raw.Lat
-			lng := raw.Lng
-			if place.Location != nil {
-				lat = place.Location.Lat
-				lng = place.Location.Lng
-			}
-			place.Category = raw.Category
-			place.Lat = lat
-			place.Lng = lng
-			if place.DisplayName != nil {
-				place.Name = place.DisplayName.Text
-			}
-			log.Printf("Processing place user_rating: %v", place.UserRatingCount)
-
-			placesBatch = append(placesBatch, place)
-
-			// Collect related data
-			for _, photo := range place.Photos {
-				photo.PlaceID = place.ID
-				photosBatch = append(photosBatch, photo)
-			}
-
-			if place.OpeningHours != nil {
-				periodsJSON, err := json.Marshal(place.OpeningHours.Periods)
-				if err != nil {
-					log.Printf("Failed to serialize opening hours periods for place %s: %v", place.ID, err)
-					continue
-				}
-				openingHoursBatch = append(openingHoursBatch, struct {
-					PlaceID string
-					Type    string
-					Periods string
-				}{place.ID, "regular", string(periodsJSON)})
-			}
-			if place.CurrentOpeningHours != nil {
-				periodsJSON, err := json.Marshal(place.CurrentOpeningHours.Periods)
-				if err != nil {
-					log.Printf("Failed to serialize current opening hours periods for place %s: %v", place.ID, err)
-					continue
-				}
-				openingHoursBatch = append(openingHoursBatch, struct {
-					PlaceID string
-					Type    string
-					Periods string
-				}{place.ID, "current", string(periodsJSON)})
-			}
-			for _, t := range place.Types {
-				placeTypesBatch = append(placeTypesBatch, struct {
-					PlaceID string
-					Type    string
-				}{place.ID, t})
-			}
-
-			// Process batch if it reaches the size limit
-			if len(placesBatch) >= batchSize {
-				if err := s.processBatch(ctx, placesBatch, photosBatch, reviewsBatch, openingHoursBatch, placeTypesBatch); err != nil {
-					log.Printf("Failed to process batch: %v", err)
-				}
-				placesBatch = nil
-				photosBatch = nil
-				reviewsBatch = nil
-				openingHoursBatch = nil
-				placeTypesBatch = nil
-			}
-		}
-	}
-
-	// Process any remaining items
-	if len(placesBatch) > 0 {
-		if err := s.processBatch(ctx, placesBatch, photosBatch, reviewsBatch, openingHoursBatch, placeTypesBatch); err != nil {
-			log.Printf("Failed to process final batch: %v", err)
-		}
-	}
-
-	return nil
-}
-
-func (s *PlacesETLService) processBatch(ctx context.Context, places []domain.Place, photos []domain.Photo, reviews []domain.Review, openingHours []struct {
-	PlaceID string
-	Type    string
-	Periods string
-}, placeTypes []struct {
-	PlaceID string
-	Type    string
-}) error {
-	err := s.pgRepo.SaveBatch(ctx, places, photos, reviews, openingHours, placeTypes)
-	if err != nil {
-		return err
-	}
-	log.Printf("Processed batch of %d places", len(places))
-	return nil
+package pipeline
+
+import (
+	"context"
+	"log"
+	"encoding/json"
+	"math"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+	"wheretoeat/internal/adapter/repository/mongodb"
+	"wheretoeat/internal/adapter/repository/postgres"
+	"wheretoeat/internal/core/domain"
+)
+
+const batchSize = 1000 // Adjust based on performance testing
+
+type PlacesETLService struct {
+	mongoRepo *mongodb.PlacesRepo
+	areasRepo *mongodb.AreasRepo
+	pgRepo    *postgres.PlacesRepo
+}
+
+func NewPlacesETLService(mongoRepo *mongodb.PlacesRepo, areasRepo *mongodb.AreasRepo, pgRepo *postgres.PlacesRepo) *PlacesETLService {
+	return &PlacesETLService{mongoRepo: mongoRepo, areasRepo: areasRepo, pgRepo: pgRepo}
+}
+
+func (s *PlacesETLService) SearchResultsToPostgres(ctx context.Context) error {
+	// Extract raw data from MongoDB
+	rawResponses, err := s.mongoRepo.GetRawPlaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Places are assigned to the city, district and ward whose boundary holds them
+	areas, err := s.areasRepo.ListAreas(ctx)
+	if err != nil {
+		return err
+	}
+	locator := domain.NewAreaLocator(areas)
+	log.Printf("Assigning places to %d areas with a boundary", locator.Len())
+
+	// The same place shows up in several overlapping circles, possibly crawled with
+	// different field masks. Keep the richest copy of each place, the newest on ties,
+	// since an upsert can't touch the same row twice.
+	var places []domain.Place
+	placeIndex := make(map[string]int)
+	categories := make(map[string][]string)
+	reviews := make(map[string][]domain.Review)
+	seenReviews := make(map[string]bool)
+
+	for _, raw := range rawResponses {
+		fieldLevel := domain.FieldLevelOf(raw.FieldMask)
+		provider := raw.Provider
+		if provider == "" {
+			provider = domain.ProviderGoogle
+		}
+		var fetchedAt *time.Time
+		if !raw.FetchedAt.IsZero() {
+			t := raw.FetchedAt // raw is reused by the loop
+			fetchedAt = &t
+		}
+		for _, place := range raw.Places {
+			// Enrich place with parent-level fields
+			lat := raw.Lat
+			lng := raw.Lng
+			if place.Location != nil {
+				lat = place.Location.Lat
+				lng = place.Location.Lng
+			}
+			place.Category = raw.Category
+			place.Lat = lat
+			place.Lng = lng
+			place.FieldLevel = fieldLevel
+			place.Provider = provider
+			place.FetchedAt = fetchedAt
+			if place.DisplayName != nil {
+				place.Name = place.DisplayName.Text
+			}
+
+			// Every raw response the place shows up in contributes its category,
+			// Text Search results may have none
+			if raw.Category != "" {
+				categories[place.ID] = append(categories[place.ID], raw.Category)
+			}
+
+			for _, review := range place.Reviews {
+				if review.Name == "" || seenReviews[review.Name] {
+					continue
+				}
+				seenReviews[review.Name] = true
+				reviews[place.ID] = append(reviews[place.ID], review.ToRow(place.ID))
+			}
+
+			i, seen := placeIndex[place.ID]
+			if !seen {
+				placeIndex[place.ID] = len(places)
+				places = append(places, place)
+			} else if isNewerCopy(place, places[i]) {
+				places[i] = place
+			}
+		}
+	}
+
+	logLikelyDuplicates(places)
+
+	// Prepare batches
+	var placesBatch []domain.Place
+	var photosBatch []domain.Photo
+	var reviewsBatch []domain.Review
+	var openingHoursBatch []struct {
+		PlaceID string
+		Type    string
+		Periods string
+	}
+	var placeTypesBatch []struct {
+		PlaceID string
+		Type    string
+	}
+	var placeCategoriesBatch []struct {
+		PlaceID  string
+		Category string
+	}
+
+	// A batch is one transaction, so a stopped ETL leaves whole batches behind
+	loaded := 0
+	for _, place := range places {
+		log.Printf("Processing place user_rating: %v", place.UserRatingCount)
+		place.City, place.District, place.Ward = locator.Locate(domain.LatLng{Lat: place.Lat, Lng: place.Lng})
+
+		placesBatch = append(placesBatch, place)
+		seenCategories := make(map[string]bool)
+		for _, category := range categories[place.ID] {
+			if seenCategories[category] {
+				continue
+			}
+			seenCategories[category] = true
+			placeCategoriesBatch = append(placeCategoriesBatch, struct {
+				PlaceID  string
+				Category string
+			}{place.ID, category})
+		}
+		reviewsBatch = append(reviewsBatch, reviews[place.ID]...)
+
+		// Collect related data
+		for _, photo := range place.Photos {
+			photo.PlaceID = place.ID
+			photosBatch = append(photosBatch, photo)
+		}
+
+		if place.OpeningHours != nil {
+			periodsJSON, err := json.Marshal(place.OpeningHours.Periods)
+			if err != nil {
+				log.Printf("Failed to serialize opening hours periods for place %s: %v", place.ID, err)
+				continue
+			}
+			openingHoursBatch = append(openingHoursBatch, struct {
+				PlaceID string
+				Type    string
+				Periods string
+			}{place.ID, "regular", string(periodsJSON)})
+		}
+		if place.CurrentOpeningHours != nil {
+			periodsJSON, err := json.Marshal(place.CurrentOpeningHours.Periods)
+			if err != nil {
+				log.Printf("Failed to serialize current opening hours periods for place %s: %v", place.ID, err)
+				continue
+			}
+			openingHoursBatch = append(openingHoursBatch, struct {
+				PlaceID string
+				Type    string
+				Periods string
+			}{place.ID, "current", string(periodsJSON)})
+		}
+		for _, t := range place.Types {
+			placeTypesBatch = append(placeTypesBatch, struct {
+				PlaceID string
+				Type    string
+			}{place.ID, t})
+		}
+
+		// Process batch if it reaches the size limit
+		if len(placesBatch) >= batchSize {
+			if err := ctx.Err(); err != nil {
+				log.Printf("ETL stopped after loading %d/%d places: %v", loaded, len(places), err)
+				return err
+			}
+			if err := s.processBatch(ctx, placesBatch, photosBatch, reviewsBatch, openingHoursBatch, placeTypesBatch, placeCategoriesBatch); err != nil {
+				log.Printf("Failed to process batch: %v", err)
+			} else {
+				loaded += len(placesBatch)
+			}
+			placesBatch = nil
+			photosBatch = nil
+			reviewsBatch = nil
+			openingHoursBatch = nil
+			placeTypesBatch = nil
+			placeCategoriesBatch = nil
+		}
+	}
+
+	// Process any remaining items
+	if len(placesBatch) > 0 {
+		if err := ctx.Err(); err != nil {
+			log.Printf("ETL stopped after loading %d/%d places: %v", loaded, len(places), err)
+			return err
+		}
+		if err := s.processBatch(ctx, placesBatch, photosBatch, reviewsBatch, openingHoursBatch, placeTypesBatch, placeCategoriesBatch); err != nil {
+			log.Printf("Failed to process final batch: %v", err)
+		} else {
+			loaded += len(placesBatch)
+		}
+	}
+
+	log.Printf("Loaded %d/%d places into PostgreSQL", loaded, len(places))
+	return nil
+}
+
+func (s *PlacesETLService) processBatch(ctx context.Context, places []domain.Place, photos []domain.Photo, reviews []domain.Review, openingHours []struct {
+	PlaceID string
+	Type    string
+	Periods string
+}, placeTypes []struct {
+	PlaceID string
+	Type    string
+}, placeCategories []struct {
+	PlaceID  string
+	Category string
+}) error {
+	err := s.pgRepo.SaveBatch(ctx, places, photos, reviews, openingHours, placeTypes, placeCategories)
+	if err != nil {
+		return err
+	}
+	log.Printf("Processed batch of %d places", len(places))
+	return nil
+}
+
+// isNewerCopy reports whether place should replace kept: a richer field mask wins,
+// and between equally rich copies the later fetch wins. Results saved before fetch
+// times were recorded count as the oldest, in the order they were stored.
+func isNewerCopy(place, kept domain.Place) bool {
+	if place.FieldLevel != kept.FieldLevel {
+		return place.FieldLevel > kept.FieldLevel
+	}
+	if kept.FetchedAt == nil {
+		return true
+	}
+	return place.FetchedAt != nil && !place.FetchedAt.Before(*kept.FetchedAt)
+}
+
+// duplicateDistance is how close an OSM place must be to a Google place of the same name
+// to be reported as a likely duplicate
+const duplicateDistance = 50.0 // meters
+
+// logLikelyDuplicates reports OSM places that are probably also in the Places API results.
+// Both copies are kept since their IDs can't be matched reliably, but they show up twice in searches.
+func logLikelyDuplicates(places []domain.Place) {
+	pairs := likelyDuplicates(places)
+	if len(pairs) == 0 {
+		return
+	}
+	log.Printf("%d OSM places are likely duplicates of a Google place, both copies are kept", len(pairs))
+	for i, pair := range pairs {
+		if i == 10 {
+			log.Printf("... and %d more", len(pairs)-i)
+			break
+		}
+		log.Printf("Likely duplicate: %s %q and %s %q", pair[0].ID, pair[0].Name, pair[1].ID, pair[1].Name)
+	}
+}
+
+// likelyDuplicates pairs OSM places with a Google place within duplicateDistance whose
+// name is the same or contains the other, e.g. a chain name and the branch's full name
+func likelyDuplicates(places []domain.Place) [][2]domain.Place {
+	// Cells of 0.001°, at least 50m wide away from the poles, so a duplicate
+	// is in the place's cell or one of its neighbours
+	type cell struct{ lat, lng int }
+	cellOf := func(p domain.Place) cell {
+		return cell{int(math.Floor(p.Lat * 1000)), int(math.Floor(p.Lng * 1000))}
+	}
+	google := make(map[cell][]domain.Place)
+	for _, p := range places {
+		if p.Provider != domain.ProviderOSM {
+			google[cellOf(p)] = append(google[cellOf(p)], p)
+		}
+	}
+
+	var pairs [][2]domain.Place
+	for _, p := range places {
+		if p.Provider != domain.ProviderOSM {
+			continue
+		}
+		c := cellOf(p)
+	neighbours:
+		for lat := c.lat - 1; lat <= c.lat+1; lat++ {
+			for lng := c.lng - 1; lng <= c.lng+1; lng++ {
+				for _, g := range google[cell{lat, lng}] {
+					a, b := domain.LatLng{Lat: p.Lat, Lng: p.Lng}, domain.LatLng{Lat: g.Lat, Lng: g.Lng}
+					if sameName(p.Name, g.Name) && domain.DistanceMeters(a, b) <= duplicateDistance {
+						pairs = append(pairs, [2]domain.Place{p, g})
+						break neighbours
+					}
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// sameName reports whether two place names are equal, or one contains the other,
+// ignoring case and spacing
+func sameName(a, b string) bool {
+	a = strings.Join(strings.Fields(strings.ToLower(norm.NFC.String(a))), " ")
+	b = strings.Join(strings.Fields(strings.ToLower(norm.NFC.String(b))), " ")
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.Contains(a, b) || strings.Contains(b, a)
 }
\ No newline at end of file
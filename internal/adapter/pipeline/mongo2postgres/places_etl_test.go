@@ -0,0 +1,36 @@
+package pipeline
+
+import (
+	"testing"
+
+	"wheretoeat/internal/core/domain"
+)
+
+func TestLikelyDuplicates(t *testing.T) {
+	place := func(id, provider, name string, northMeters float64) domain.Place {
+		return domain.Place{ID: id, Provider: provider, Name: name, Lat: 10.7769 + northMeters*domain.LatMeterToDegree, Lng: 106.7009}
+	}
+	google := place("g1", domain.ProviderGoogle, "Highlands Coffee Nguyễn Huệ", 0)
+
+	tests := []struct {
+		name   string
+		places []domain.Place
+		want   int
+	}{
+		{"same name nearby", []domain.Place{google, place("osm/node/1", domain.ProviderOSM, "Highlands Coffee Nguyễn Huệ", 20)}, 1},
+		{"chain name nearby", []domain.Place{google, place("osm/node/1", domain.ProviderOSM, "highlands coffee", 40)}, 1},
+		{"same name too far", []domain.Place{google, place("osm/node/1", domain.ProviderOSM, "Highlands Coffee Nguyễn Huệ", 200)}, 0},
+		{"other name nearby", []domain.Place{google, place("osm/node/1", domain.ProviderOSM, "Phở Hòa", 10)}, 0},
+		{"in the next grid cell", []domain.Place{google, place("osm/node/1", domain.ProviderOSM, "Highlands Coffee", 15)}, 1},
+		{"two Google places", []domain.Place{google, place("g2", domain.ProviderGoogle, "Highlands Coffee Nguyễn Huệ", 10)}, 0},
+		{"unnamed", []domain.Place{place("g2", domain.ProviderGoogle, "", 0), place("osm/node/1", domain.ProviderOSM, "", 0)}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := likelyDuplicates(tt.places); len(got) != tt.want {
+				t.Errorf("got %d duplicates %v, want %d", len(got), got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// Defaults match the Places API's default quota of 600 requests per minute
+const (
+	defaultQPS           = 10
+	defaultBurst         = 5
+	defaultMaxConcurrent = 10
+)
+
+// Limiter is a token bucket that paces requests to a sustained rate with bursts,
+// plus a cap on requests in flight. It is safe for concurrent use.
+type Limiter struct {
+	limits domain.RateLimits
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+
+	slots chan struct{} // nil without a concurrency cap
+}
+
+func NewLimiter(limits domain.RateLimits) *Limiter {
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+	l := &Limiter{limits: limits, tokens: float64(limits.Burst), last: time.Now()}
+	if limits.MaxConcurrent > 0 {
+		l.slots = make(chan struct{}, limits.MaxConcurrent)
+	}
+	return l
+}
+
+// LimitsFromEnv reads the limits from GOOGLE_MAX_QPS, GOOGLE_BURST and
+// GOOGLE_MAX_CONCURRENT_REQUESTS. Unset variables get the defaults, 0 means no limit.
+func LimitsFromEnv() (domain.RateLimits, error) {
+	limits := domain.RateLimits{QPS: defaultQPS, Burst: defaultBurst, MaxConcurrent: defaultMaxConcurrent}
+	if value := os.Getenv("GOOGLE_MAX_QPS"); value != "" {
+		qps, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return limits, fmt.Errorf("invalid GOOGLE_MAX_QPS: %w", err)
+		}
+		limits.QPS = qps
+	}
+	if value := os.Getenv("GOOGLE_BURST"); value != "" {
+		burst, err := strconv.Atoi(value)
+		if err != nil {
+			return limits, fmt.Errorf("invalid GOOGLE_BURST: %w", err)
+		}
+		limits.Burst = burst
+	}
+	if value := os.Getenv("GOOGLE_MAX_CONCURRENT_REQUESTS"); value != "" {
+		maxConcurrent, err := strconv.Atoi(value)
+		if err != nil {
+			return limits, fmt.Errorf("invalid GOOGLE_MAX_CONCURRENT_REQUESTS: %w", err)
+		}
+		limits.MaxConcurrent = maxConcurrent
+	}
+	return limits, nil
+}
+
+// Limits returns the limits the limiter enforces
+func (l *Limiter) Limits() domain.RateLimits {
+	return l.limits
+}
+
+// Acquire blocks until a request may be sent and returns a func to call once it
+// has completed. It fails only if the context is done first.
+func (l *Limiter) Acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		release = func() { <-l.slots }
+	}
+	if err := l.wait(ctx); err != nil {
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+// wait takes a token, sleeping until the bucket has refilled enough to cover it
+func (l *Limiter) wait(ctx context.Context) error {
+	if l.limits.QPS <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(float64(l.limits.Burst), l.tokens+now.Sub(l.last).Seconds()*l.limits.QPS)
+	l.last = now
+	// The token is taken right away, going negative means waiting until it's paid back
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.limits.QPS * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++ // Give back the unused token
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
\ No newline at end of file
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+)
+
+func TestLimiterRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		limits   domain.RateLimits
+		requests int
+		min, max time.Duration // Bounds of the time all requests take
+	}{
+		{"no limit", domain.RateLimits{}, 100, 0, 50 * time.Millisecond},
+		{"burst goes out at once", domain.RateLimits{QPS: 10, Burst: 5}, 5, 0, 50 * time.Millisecond},
+		{"past the burst requests wait for the rate", domain.RateLimits{QPS: 10, Burst: 5}, 7, 180 * time.Millisecond, 400 * time.Millisecond},
+		{"sustained rate", domain.RateLimits{QPS: 50, Burst: 1}, 6, 90 * time.Millisecond, 300 * time.Millisecond},
+		{"burst below 1 counts as 1", domain.RateLimits{QPS: 50}, 2, 15 * time.Millisecond, 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.limits)
+			start := time.Now()
+			for i := 0; i < tt.requests; i++ {
+				release, err := l.Acquire(context.Background())
+				if err != nil {
+					t.Fatalf("request %d: %v", i, err)
+				}
+				release()
+			}
+			if elapsed := time.Since(start); elapsed < tt.min || elapsed > tt.max {
+				t.Errorf("%d requests took %v, want between %v and %v", tt.requests, elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	l := NewLimiter(domain.RateLimits{MaxConcurrent: 2})
+	first, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("third request in flight got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	// A released slot lets the next request through
+	first()
+	third, err := l.Acquire(context.Background())
+	if err != nil {
+		t.Fatalf("after a release got %v", err)
+	}
+	second()
+	third()
+	if len(l.slots) != 0 {
+		t.Errorf("%d slots still taken after every release", len(l.slots))
+	}
+}
+
+func TestLimiterCancellation(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits domain.RateLimits
+	}{
+		{"waiting for a slot", domain.RateLimits{MaxConcurrent: 1}},
+		{"waiting for a token", domain.RateLimits{QPS: 1, Burst: 1}},
+		{"waiting for a token with a slot", domain.RateLimits{QPS: 1, Burst: 1, MaxConcurrent: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.limits)
+			// Takes the only token, and holds the only slot unless there's a rate to wait for
+			release, err := l.Acquire(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.limits.QPS > 0 {
+				release()
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(20*time.Millisecond, cancel)
+			start := time.Now()
+			if _, err := l.Acquire(ctx); !errors.Is(err, context.Canceled) {
+				t.Fatalf("got %v, want %v", err, context.Canceled)
+			}
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("cancelled request returned after %v", elapsed)
+			}
+
+			if tt.limits.QPS <= 0 {
+				release()
+			}
+			// The cancelled request gave back its slot and its token
+			if len(l.slots) != 0 {
+				t.Errorf("%d slots still taken", len(l.slots))
+			}
+			if l.tokens < -0.5 {
+				t.Errorf("token bucket at %.2f, the cancelled request kept its token", l.tokens)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// APIUsageRepo keeps one document of Places API usage per day
+type APIUsageRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAPIUsageRepo(client *mongo.Client) *APIUsageRepo {
+	mongoDB := os.Getenv("MONGO_DB")
+	mongoCollection := os.Getenv("MONGO_COLLECTION_API_USAGE")
+	return &APIUsageRepo{
+		collection: client.Database(mongoDB).Collection(mongoCollection),
+	}
+}
+
+// GetUsage returns the usage of day, or an empty usage if nothing was recorded yet
+func (r *APIUsageRepo) GetUsage(ctx context.Context, day string) (domain.APIUsage, error) {
+	var usage domain.APIUsage
+	err := r.collection.FindOne(ctx, bson.M{"date": day}).Decode(&usage)
+	if err == mongo.ErrNoDocuments {
+		return domain.APIUsage{Date: day}, nil
+	}
+	if err != nil {
+		return usage, fmt.Errorf("error finding API usage in MongoDB: %w", err)
+	}
+	return usage, nil
+}
+
+// EnsureIndexes makes the date unique, so concurrent runs share one document per day
+func (r *APIUsageRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("error creating API usage indexes in MongoDB: %w", err)
+	}
+	return nil
+}
+
+// ReserveUsage atomically adds one request of sku to the usage of day unless that would
+// go over maxRequests or maxCost, where 0 means no cap, and reports whether it was added.
+// The caps are checked by the update filter itself, so concurrent runs can't overshoot them.
+func (r *APIUsageRepo) ReserveUsage(ctx context.Context, day string, sku domain.SKU, cost float64, maxRequests int, maxCost float64) (bool, error) {
+	// Create the day's document first, since an upsert would insert a second
+	// one whenever the caps below don't match the existing one
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"date": day},
+		bson.M{"$setOnInsert": bson.M{"requests": 0, "cost": 0.0, "updatedAt": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil && !mongo.IsDuplicateKeyError(err) { // Another run created it first
+		return false, fmt.Errorf("error creating API usage in MongoDB: %w", err)
+	}
+
+	filter := bson.M{"date": day}
+	if maxRequests > 0 {
+		filter["requests"] = bson.M{"$lt": maxRequests}
+	}
+	if maxCost > 0 {
+		filter["cost"] = bson.M{"$lte": maxCost - cost}
+	}
+	err = r.collection.FindOneAndUpdate(ctx,
+		filter,
+		bson.M{
+			"$inc": bson.M{"requests": 1, "cost": cost, "skus." + string(sku): 1},
+			"$set": bson.M{"updatedAt": time.Now()},
+		},
+	).Err()
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error recording API usage in MongoDB: %w", err)
+	}
+	return true, nil
+}
@@ -1,45 +1,134 @@
-package mongodb
-
-import (
-	"context"
-	"os"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-)
-
-// AreasRepo handles interactions with the 'areas' collection in MongoDB
-type AreasRepo struct {
-	collection *mongo.Collection
-}
-
-// NewAreasRepo initializes a new AreasRepo with a MongoDB client and database name
-func NewAreasRepo(client *mongo.Client) *AreasRepo {
-	mongoDB := os.Getenv("MONGO_DB")
-	mongoCollection := os.Getenv("MONGO_COLLECTION_AREAS")
-	return &AreasRepo{
-		collection: client.Database(mongoDB).Collection(mongoCollection),
-	}
-}
-
-// SaveArea saves an area document to the 'areas' collection
-func (r *AreasRepo) SaveArea(ctx context.Context, area bson.M) error {
-	_, err := r.collection.InsertOne(ctx, area)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-// GetAreaByPlaceID retrieves an area by its placeID
-func (r *AreasRepo) GetAreaByPlaceID(ctx context.Context, placeID string) (bson.M, error) {
-	var result bson.M
-	err := r.collection.FindOne(ctx, bson.M{"placeID": placeID}).Decode(&result)
-	if err == mongo.ErrNoDocuments {
-		return nil, nil // Not found, return nil instead of error
-	}
-	if err != nil {
-		return nil, err
-	}
-	return result, nil
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// AreasRepo handles interactions with the 'areas' collection in MongoDB
+type AreasRepo struct {
+	collection *mongo.Collection
+}
+
+// NewAreasRepo initializes a new AreasRepo with a MongoDB client and database name
+func NewAreasRepo(client *mongo.Client) *AreasRepo {
+	mongoDB := os.Getenv("MONGO_DB")
+	mongoCollection := os.Getenv("MONGO_COLLECTION_AREAS")
+	return &AreasRepo{
+		collection: client.Database(mongoDB).Collection(mongoCollection),
+	}
+}
+
+// SaveArea saves an area document to the 'areas' collection
+func (r *AreasRepo) SaveArea(ctx context.Context, area bson.M) error {
+	_, err := r.collection.InsertOne(ctx, area)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetAreaByPlaceID retrieves an area by its placeID
+func (r *AreasRepo) GetAreaByPlaceID(ctx context.Context, placeID string) (bson.M, error) {
+	var result bson.M
+	err := r.collection.FindOne(ctx, bson.M{"placeID": placeID}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil // Not found, return nil instead of error
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SetAreaBoundary stores the real outline of an area, used to limit crawls to it
+func (r *AreasRepo) SetAreaBoundary(ctx context.Context, placeID string, boundary domain.Boundary) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"placeID": placeID}, bson.M{"$set": bson.M{"boundary": boundary}})
+	if err != nil {
+		return fmt.Errorf("error updating area boundary in MongoDB: %w", err)
+	}
+	return nil
+}
+
+// UpsertArea replaces every saved copy of the area with this one
+func (r *AreasRepo) UpsertArea(ctx context.Context, area domain.Area) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"placeID": area.PlaceID}); err != nil {
+		return fmt.Errorf("error replacing area %s in MongoDB: %w", area.Name, err)
+	}
+	if _, err := r.collection.InsertOne(ctx, area); err != nil {
+		return fmt.Errorf("error saving area %s in MongoDB: %w", area.Name, err)
+	}
+	return nil
+}
+
+// SetAreaParent links an area to the area one level up that contains it, empty to unlink it
+func (r *AreasRepo) SetAreaParent(ctx context.Context, placeID, parentID string) error {
+	update := bson.M{"$set": bson.M{"parentID": parentID}}
+	if parentID == "" {
+		update = bson.M{"$unset": bson.M{"parentID": ""}}
+	}
+	if _, err := r.collection.UpdateMany(ctx, bson.M{"placeID": placeID}, update); err != nil {
+		return fmt.Errorf("error updating area parent in MongoDB: %w", err)
+	}
+	return nil
+}
+
+// ListAreas returns every saved area, keeping only the latest fetch of each place
+func (r *AreasRepo) ListAreas(ctx context.Context) ([]domain.Area, error) {
+	return r.findAreas(ctx, bson.M{})
+}
+
+// SearchAreas returns the saved areas whose name or fetch query contains text, ignoring case
+func (r *AreasRepo) SearchAreas(ctx context.Context, text string) ([]domain.Area, error) {
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(text), Options: "i"}
+	return r.findAreas(ctx, bson.M{"$or": []bson.M{{"name": pattern}, {"query": pattern}}})
+}
+
+// GetAreaByName retrieves the latest area whose name or fetch query is exactly name, ignoring case
+func (r *AreasRepo) GetAreaByName(ctx context.Context, name string) (*domain.Area, error) {
+	pattern := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(name) + "$", Options: "i"}
+	var area domain.Area
+	err := r.collection.FindOne(ctx, bson.M{"$or": []bson.M{{"name": pattern}, {"query": pattern}}},
+		options.FindOne().SetSort(bson.M{"timestamp": -1})).Decode(&area)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil // Not found, return nil instead of error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding area %s in MongoDB: %w", name, err)
+	}
+	return &area, nil
+}
+
+func (r *AreasRepo) findAreas(ctx context.Context, filter bson.M) ([]domain.Area, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"timestamp": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("error finding areas in MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var found []domain.Area
+	if err = cursor.All(ctx, &found); err != nil {
+		return nil, fmt.Errorf("error decoding areas from MongoDB: %w", err)
+	}
+
+	// run-fetch-areas inserts a new document on every run, newest first here
+	areas := []domain.Area{}
+	seen := make(map[string]bool)
+	for _, area := range found {
+		if seen[area.PlaceID] {
+			continue
+		}
+		seen[area.PlaceID] = true
+		areas = append(areas, area)
+	}
+	return areas, nil
 }
\ No newline at end of file
@@ -0,0 +1,146 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"wheretoeat/internal/core/domain"
+)
+
+// CrawlJobsRepo persists crawl jobs and the frontier of circles of each job
+type CrawlJobsRepo struct {
+	jobsCollection    *mongo.Collection
+	circlesCollection *mongo.Collection
+}
+
+func NewCrawlJobsRepo(client *mongo.Client) *CrawlJobsRepo {
+	mongoDB := os.Getenv("MONGO_DB")
+	return &CrawlJobsRepo{
+		jobsCollection:    client.Database(mongoDB).Collection(os.Getenv("MONGO_COLLECTION_CRAWL_JOBS")),
+		circlesCollection: client.Database(mongoDB).Collection(os.Getenv("MONGO_COLLECTION_CRAWL_CIRCLES")),
+	}
+}
+
+// CreateJob saves a new running job and returns its ID
+func (r *CrawlJobsRepo) CreateJob(ctx context.Context, job domain.CrawlJob) (string, error) {
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	job.Status = domain.CrawlJobRunning
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if _, err := r.jobsCollection.InsertOne(ctx, job); err != nil {
+		return "", fmt.Errorf("error inserting crawl job into MongoDB: %w", err)
+	}
+	return job.ID.Hex(), nil
+}
+
+// GetJob returns the job with the given ID, or nil if there is none
+func (r *CrawlJobsRepo) GetJob(ctx context.Context, jobID string) (*domain.CrawlJob, error) {
+	id, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid crawl job id %s: %w", jobID, err)
+	}
+	var job domain.CrawlJob
+	err = r.jobsCollection.FindOne(ctx, bson.M{"_id": id}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil // Not found, return nil instead of error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding crawl job in MongoDB: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *CrawlJobsRepo) UpdateJobStatus(ctx context.Context, jobID string, status string) error {
+	id, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return fmt.Errorf("invalid crawl job id %s: %w", jobID, err)
+	}
+	_, err = r.jobsCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"status": status, "updatedAt": time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("error updating crawl job in MongoDB: %w", err)
+	}
+	return nil
+}
+
+// AddCircles adds circles to the job's frontier as pending. Circles already in the frontier are left untouched.
+func (r *CrawlJobsRepo) AddCircles(ctx context.Context, jobID string, circles []domain.Circle) error {
+	if len(circles) == 0 {
+		return nil
+	}
+	id, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return fmt.Errorf("invalid crawl job id %s: %w", jobID, err)
+	}
+
+	now := time.Now()
+	models := make([]mongo.WriteModel, 0, len(circles))
+	for _, c := range circles {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(circleFilter(id, c)).
+			SetUpdate(bson.M{"$setOnInsert": domain.CrawlCircle{
+				JobID:     id,
+				Lat:       c.Lat,
+				Lng:       c.Lng,
+				Radius:    c.Radius,
+				Status:    domain.CrawlCirclePending,
+				UpdatedAt: now,
+			}}).
+			SetUpsert(true))
+	}
+	_, err = r.circlesCollection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return fmt.Errorf("error adding crawl circles to MongoDB: %w", err)
+	}
+	return nil
+}
+
+// MarkCircle records the outcome of crawling one circle of the job
+func (r *CrawlJobsRepo) MarkCircle(ctx context.Context, jobID string, circle domain.Circle, status string, numPlaces int64, errMsg string) error {
+	id, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return fmt.Errorf("invalid crawl job id %s: %w", jobID, err)
+	}
+	_, err = r.circlesCollection.UpdateOne(ctx, circleFilter(id, circle), bson.M{
+		"$set": bson.M{"status": status, "numPlaces": numPlaces, "error": errMsg, "updatedAt": time.Now()},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("error updating crawl circle in MongoDB: %w", err)
+	}
+	return nil
+}
+
+// GetUnfinishedCircles returns the pending and failed circles of the job, largest first
+func (r *CrawlJobsRepo) GetUnfinishedCircles(ctx context.Context, jobID string) ([]domain.CrawlCircle, error) {
+	id, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid crawl job id %s: %w", jobID, err)
+	}
+	cursor, err := r.circlesCollection.Find(ctx, bson.M{
+		"jobId":  id,
+		"status": bson.M{"$in": []string{domain.CrawlCirclePending, domain.CrawlCircleFailed}},
+	}, options.Find().SetSort(bson.M{"radius": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("error finding crawl circles in MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var circles []domain.CrawlCircle
+	if err = cursor.All(ctx, &circles); err != nil {
+		return nil, fmt.Errorf("error decoding crawl circles from MongoDB: %w", err)
+	}
+	return circles, nil
+}
+
+func circleFilter(jobID primitive.ObjectID, c domain.Circle) bson.M {
+	return bson.M{"jobId": jobID, "lat": c.Lat, "lng": c.Lng, "radius": c.Radius}
+}
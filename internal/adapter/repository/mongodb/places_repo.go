@@ -1,162 +1,361 @@
-package mongodb
-
-import (
-	"context"
-	"fmt"
-	"os"
-	"math"
-	"log"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
-
-	"wheretoeat/internal/core/domain"
-)
-
-type PlacesRepo struct {
-	client           *mongo.Client
-	placesCollection *mongo.Collection
-}
-
-func NewMongoAdapter() (*mongo.Client, error) {
-	mongoURI := os.Getenv("MONGO_URI")
-	clientOptions := options.Client().ApplyURI(mongoURI)
-	client, err := mongo.Connect(context.TODO(), clientOptions)
-	if err != nil {
-		return nil, fmt.Errorf("error connecting to MongoDB: %w", err)
-	}
-	return client, nil
-}
-
-func NewPlacesRepo(client *mongo.Client) *PlacesRepo {
-	mongoDB := os.Getenv("MONGO_DB")
-	mongoPlacesCollection := os.Getenv("MONGO_COLLECTION_SEARCH_RESULTS")
-	return &PlacesRepo{
-		client:           client,
-		placesCollection: client.Database(mongoDB).Collection(mongoPlacesCollection),
-	}
-}
-
-func (r *PlacesRepo) SaveSearchResults(ctx context.Context, category string, circle domain.Circle, places []interface{}) error {
-	_, err := r.placesCollection.InsertOne(ctx, bson.M{
-		"category": category,
-		"lat":      circle.Lat,
-		"lng":      circle.Lng,
-		"radius":   circle.Radius,
-		"places":   places,
-	})
-	if err != nil {
-		return fmt.Errorf("error inserting places into MongoDB: %w", err)
-	}
-	return nil
-}
-
-func (r *PlacesRepo) GetNumPlaces(ctx context.Context, category string, circle domain.Circle) (int64, error) {
-	// Find one document matching the category and circle
-	var result struct {
-		Places []interface{} `bson:"places"`
-	}
-	err := r.placesCollection.FindOne(ctx, bson.M{
-		"category": category,
-		"lat":      circle.Lat,
-		"lng":      circle.Lng,
-		"radius":   circle.Radius,
-	}).Decode(&result)
-	if err == mongo.ErrNoDocuments {
-		// No matching document found, return 0
-		return 0, nil
-	}
-	if err != nil {
-		return 0, fmt.Errorf("error finding document in MongoDB: %w", err)
-	}
-
-	// Return the length of the 'places' array
-	return int64(len(result.Places)), nil
-}
-
-func (r *PlacesRepo) GetRawPlaces(ctx context.Context) ([]domain.PlacesRawResponse, error) {
-	cursor, err := r.placesCollection.Find(ctx, bson.M{})
-	if err != nil {
-		return nil, fmt.Errorf("error finding documents in MongoDB: %w", err)
-	}
-	defer cursor.Close(ctx)
-
-	var documents []domain.PlacesRawResponse
-	if err = cursor.All(ctx, &documents); err != nil {
-		return nil, fmt.Errorf("error decoding documents in MongoDB: %w", err)
-	}
-
-	return documents, nil
-}
-
-func (r *PlacesRepo) AreaHasBeenScanned(ctx context.Context, category string, circle domain.Circle) (bool, error) {
-    // Find all circles that might overlap with the given circle
-	log.Println("Searching for overlapping circles...")
-    var existingCircles []domain.Circle
-    cursor, err := r.placesCollection.Find(ctx, bson.M{
-        "$and": []bson.M{
-            {"lat": bson.M{"$gte": circle.Lat - circle.Radius, "$lte": circle.Lat + circle.Radius}},
-            {"lng": bson.M{"$gte": circle.Lng - circle.Radius, "$lte": circle.Lng + circle.Radius}},
-			{"category": category},
-        },
-    })
-	log.Println("Number of overlapping circles found: ", cursor.RemainingBatchLength())
-
-    if err != nil {
-        return false, fmt.Errorf("error finding overlapping circles in MongoDB: %w", err)
-    }
-    defer cursor.Close(ctx)
-
-    if err = cursor.All(ctx, &existingCircles); err != nil {
-        return false, fmt.Errorf("error decoding circles from MongoDB: %w", err)
-    }
-
-    // Calculate total overlapped area
-    givenArea := math.Pi * circle.Radius * circle.Radius
-    coveredArea := 0.0
-
-    for _, existing := range existingCircles {
-        overlap := calculateCircleIntersectionArea(circle, existing)
-        coveredArea += overlap
-    }
-	log.Printf("Percentage of area covered: %.2f%%\n", (coveredArea/givenArea)*100)
-    return coveredArea >= givenArea, nil
-}
-
-// calculateCircleIntersectionArea calculates the overlapping area between two circles
-func calculateCircleIntersectionArea(c1, c2 domain.Circle) float64 {
-    d := haversineDistance(c1.Lat, c1.Lng, c2.Lat, c2.Lng)
-    
-    if d >= c1.Radius+c2.Radius {
-        return 0 // No overlap
-    }
-    
-    if d <= math.Abs(c1.Radius-c2.Radius) {
-        // One circle is completely inside the other
-        return math.Pi * math.Min(c1.Radius, c2.Radius) * math.Min(c1.Radius, c2.Radius)
-    }
-    
-    r1, r2 := c1.Radius, c2.Radius
-    
-    a1 := r1 * r1 * math.Acos((d*d + r1*r1 - r2*r2) / (2 * d * r1))
-    a2 := r2 * r2 * math.Acos((d*d + r2*r2 - r1*r1) / (2 * d * r2))
-    a3 := 0.5 * math.Sqrt((-d+r1+r2) * (d+r1-r2) * (d-r1+r2) * (d+r1+r2))
-    
-    return a1 + a2 - a3
-}
-
-// haversineDistance calculates the great-circle distance between two points
-func haversineDistance(lat1, lon1, lat2, lon2 float64) float64 {
-    const R = 6371e3 // Earth radius in meters
-    
-    dLat := (lat2 - lat1) * (math.Pi / 180)
-    dLon := (lon2 - lon1) * (math.Pi / 180)
-    
-    a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-        math.Cos(lat1*(math.Pi/180))*math.Cos(lat2*(math.Pi/180))*
-            math.Sin(dLon/2)*math.Sin(dLon/2)
-    
-    c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-    return R * c
-}
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"wheretoeat/internal/core/domain"
+)
+
+type PlacesRepo struct {
+	client           *mongo.Client
+	placesCollection *mongo.Collection
+}
+
+func NewMongoAdapter() (*mongo.Client, error) {
+	mongoURI := os.Getenv("MONGO_URI")
+	clientOptions := options.Client().ApplyURI(mongoURI)
+	client, err := mongo.Connect(context.TODO(), clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to MongoDB: %w", err)
+	}
+	return client, nil
+}
+
+func NewPlacesRepo(client *mongo.Client) *PlacesRepo {
+	mongoDB := os.Getenv("MONGO_DB")
+	mongoPlacesCollection := os.Getenv("MONGO_COLLECTION_SEARCH_RESULTS")
+	return &PlacesRepo{
+		client:           client,
+		placesCollection: client.Database(mongoDB).Collection(mongoPlacesCollection),
+	}
+}
+
+func (r *PlacesRepo) SaveSearchResults(ctx context.Context, category string, circle domain.Circle, fieldMask domain.FieldMask, places []domain.PlaceResult) error {
+	raw, err := rawPlaces(places)
+	if err != nil {
+		return err
+	}
+	_, err = r.placesCollection.InsertOne(ctx, bson.M{
+		"category":   category,
+		"fieldMask":  fieldMask.Name,
+		"fieldLevel": fieldMask.Level,
+		"lat":        circle.Lat,
+		"lng":        circle.Lng,
+		"radius":     circle.Radius,
+		"location":   geoPoint(circle.Lat, circle.Lng),
+		"places":     raw,
+		"fetchedAt":  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting places into MongoDB: %w", err)
+	}
+	return nil
+}
+
+// SaveTextSearchResults stores every page of a Text Search for query restricted to the circle
+func (r *PlacesRepo) SaveTextSearchResults(ctx context.Context, query, category string, circle domain.Circle, fieldMask domain.FieldMask, places []domain.PlaceResult) error {
+	raw, err := rawPlaces(places)
+	if err != nil {
+		return err
+	}
+	_, err = r.placesCollection.InsertOne(ctx, bson.M{
+		"query":      query,
+		"category":   category,
+		"fieldMask":  fieldMask.Name,
+		"fieldLevel": fieldMask.Level,
+		"lat":        circle.Lat,
+		"lng":        circle.Lng,
+		"radius":     circle.Radius,
+		"location":   geoPoint(circle.Lat, circle.Lng),
+		"places":     raw,
+		"fetchedAt":  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting text search results into MongoDB: %w", err)
+	}
+	return nil
+}
+
+// rawPlaces returns the places as Google sent them, which is what search results store
+// and what the ETL decodes, rather than the fields Place happens to keep
+func rawPlaces(places []domain.PlaceResult) ([]interface{}, error) {
+	raw := make([]interface{}, 0, len(places))
+	for _, place := range places {
+		var fields map[string]interface{}
+		if err := json.Unmarshal(place.Raw, &fields); err != nil {
+			return nil, fmt.Errorf("error decoding raw place %s: %w", place.Place.ID, err)
+		}
+		raw = append(raw, fields)
+	}
+	return raw, nil
+}
+
+// GetTextSearchNumPlaces returns how many places an earlier Text Search for query over the same
+// circle found with at least fieldLevel, and whether there was one
+func (r *PlacesRepo) GetTextSearchNumPlaces(ctx context.Context, query string, circle domain.Circle, fieldLevel int) (int64, bool, error) {
+	var result struct {
+		Places []interface{} `bson:"places"`
+	}
+	err := r.placesCollection.FindOne(ctx, bson.M{
+		"query":      query,
+		"lat":        circle.Lat,
+		"lng":        circle.Lng,
+		"radius":     circle.Radius,
+		"fieldLevel": bson.M{"$gte": fieldLevel},
+	}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("error finding text search results in MongoDB: %w", err)
+	}
+	return int64(len(result.Places)), true, nil
+}
+
+// SaveImportedPlaces stores places imported from another provider as a raw response for the ETL.
+// They have no search circle, so they never count as coverage for a Places API crawl.
+func (r *PlacesRepo) SaveImportedPlaces(ctx context.Context, provider, source, category string, places []interface{}) error {
+	_, err := r.placesCollection.InsertOne(ctx, bson.M{
+		"provider":   provider,
+		"source":     source,
+		"category":   category,
+		"fieldMask":  domain.FieldMaskBasic.Name,
+		"fieldLevel": domain.FieldMaskBasic.Level,
+		"places":     places,
+		"fetchedAt":  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error inserting imported places into MongoDB: %w", err)
+	}
+	return nil
+}
+
+// DeleteImportedPlaces removes an earlier import of the same source, so importing it again replaces it
+func (r *PlacesRepo) DeleteImportedPlaces(ctx context.Context, provider, source string) error {
+	_, err := r.placesCollection.DeleteMany(ctx, bson.M{"provider": provider, "source": source})
+	if err != nil {
+		return fmt.Errorf("error deleting imported places from MongoDB: %w", err)
+	}
+	return nil
+}
+
+func (r *PlacesRepo) GetNumPlaces(ctx context.Context, category string, circle domain.Circle) (int64, error) {
+	// Find one document matching the category and circle
+	var result struct {
+		Places []interface{} `bson:"places"`
+	}
+	err := r.placesCollection.FindOne(ctx, bson.M{
+		"category": category,
+		"lat":      circle.Lat,
+		"lng":      circle.Lng,
+		"radius":   circle.Radius,
+		"query":    bson.M{"$exists": false},
+	}).Decode(&result)
+	if err == mongo.ErrNoDocuments {
+		// No matching document found, return 0
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error finding document in MongoDB: %w", err)
+	}
+
+	// Return the length of the 'places' array
+	return int64(len(result.Places)), nil
+}
+
+func (r *PlacesRepo) GetRawPlaces(ctx context.Context) ([]domain.PlacesRawResponse, error) {
+	cursor, err := r.placesCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error finding documents in MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var documents []domain.PlacesRawResponse
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("error decoding documents in MongoDB: %w", err)
+	}
+
+	return documents, nil
+}
+
+// GetStaleCircles returns up to limit Nearby Search circles crawled with the given field mask that were last
+// fetched before olderThan, oldest first. Results saved before fetch times were recorded come first.
+// An empty category matches every category. Imported places have no circle and are never returned.
+func (r *PlacesRepo) GetStaleCircles(ctx context.Context, category string, fieldMask domain.FieldMask, olderThan time.Time, limit int) ([]domain.SearchCircle, error) {
+	sameMask := []bson.M{{"fieldMask": fieldMask.Name}}
+	if fieldMask.Name == domain.FieldMaskFull.Name {
+		// Results saved before field masks existed were crawled with every field
+		sameMask = append(sameMask, bson.M{"fieldMask": bson.M{"$exists": false}})
+	}
+	filter := bson.M{
+		"radius":    bson.M{"$gt": 0},
+		"query":     bson.M{"$exists": false},
+		"fetchedAt": bson.M{"$not": bson.M{"$gte": olderThan}},
+		"$or":       sameMask,
+	}
+	if category != "" {
+		filter["category"] = category
+	}
+
+	return r.findSearchCircles(ctx, filter, options.Find().SetSort(bson.M{"fetchedAt": 1}).SetLimit(int64(limit)))
+}
+
+// GetSearchCircles returns every Nearby Search circle stored for the category
+func (r *PlacesRepo) GetSearchCircles(ctx context.Context, category string) ([]domain.SearchCircle, error) {
+	filter := bson.M{
+		"category": category,
+		"radius":   bson.M{"$gt": 0},
+		"query":    bson.M{"$exists": false},
+	}
+	return r.findSearchCircles(ctx, filter, options.Find())
+}
+
+// findSearchCircles reads the circles of the matching search results with the IDs of their places
+func (r *PlacesRepo) findSearchCircles(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]domain.SearchCircle, error) {
+	cursor, err := r.placesCollection.Find(ctx, filter, opts.
+		SetProjection(bson.M{"category": 1, "lat": 1, "lng": 1, "radius": 1, "fieldMask": 1, "fetchedAt": 1, "places.id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("error finding search circles in MongoDB: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var documents []struct {
+		ID        primitive.ObjectID `bson:"_id"`
+		Category  string             `bson:"category"`
+		Lat       float64            `bson:"lat"`
+		Lng       float64            `bson:"lng"`
+		Radius    float64            `bson:"radius"`
+		FieldMask string             `bson:"fieldMask"`
+		FetchedAt time.Time          `bson:"fetchedAt"`
+		Places    []struct {
+			ID string `bson:"id"`
+		} `bson:"places"`
+	}
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("error decoding search circles from MongoDB: %w", err)
+	}
+
+	circles := make([]domain.SearchCircle, 0, len(documents))
+	for _, doc := range documents {
+		circle := domain.SearchCircle{
+			ID:        doc.ID.Hex(),
+			Category:  doc.Category,
+			Circle:    domain.Circle{Lat: doc.Lat, Lng: doc.Lng, Radius: doc.Radius},
+			FieldMask: doc.FieldMask,
+			FetchedAt: doc.FetchedAt,
+		}
+		for _, place := range doc.Places {
+			circle.PlaceIDs = append(circle.PlaceIDs, place.ID)
+		}
+		circles = append(circles, circle)
+	}
+	return circles, nil
+}
+
+// UpdateSearchResults replaces the places of an earlier search with a fresh fetch of the same circle
+func (r *PlacesRepo) UpdateSearchResults(ctx context.Context, id string, places []domain.PlaceResult) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid search results id %s: %w", id, err)
+	}
+	raw, err := rawPlaces(places)
+	if err != nil {
+		return err
+	}
+	_, err = r.placesCollection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{
+		"$set": bson.M{"places": raw, "fetchedAt": time.Now()},
+	})
+	if err != nil {
+		return fmt.Errorf("error updating search results in MongoDB: %w", err)
+	}
+	return nil
+}
+
+// AreaHasBeenScanned reports whether earlier circles crawled with at least fieldLevel
+// already cover the circle, see domain.ScannedFraction. Only the part of the circle
+// inside the boundary needs to be covered.
+func (r *PlacesRepo) AreaHasBeenScanned(ctx context.Context, category string, circle domain.Circle, boundary domain.Boundary, fieldLevel int) (bool, error) {
+	// Results saved before field masks existed have no level and were crawled with every field
+	richEnough := bson.M{"$or": []bson.M{
+		{"fieldLevel": bson.M{"$gte": fieldLevel}},
+		{"fieldLevel": bson.M{"$exists": false}},
+	}}
+
+	// Text Search results only hold places matching their query, so they cover nothing
+	nearbySearch := bson.M{"$exists": false}
+
+	// Saturated circles cover nothing, so the largest one doesn't widen the search.
+	// A circle is saturated when it has a place at index MaxResultsPerReq-1.
+	notSaturated := fmt.Sprintf("places.%d", domain.MaxResultsPerReq-1)
+
+	// A stored circle overlaps this one when its center is closer than the sum of both radii
+	var largest struct {
+		Radius float64 `bson:"radius"`
+	}
+	err := r.placesCollection.FindOne(ctx, bson.M{"category": category, "query": nearbySearch, notSaturated: bson.M{"$exists": false}, "$and": []bson.M{richEnough}},
+		options.FindOne().SetSort(bson.M{"radius": -1}).SetProjection(bson.M{"radius": 1})).Decode(&largest)
+	if err == mongo.ErrNoDocuments {
+		return false, nil // Nothing scanned yet for this category
+	}
+	if err != nil {
+		return false, fmt.Errorf("error finding largest circle in MongoDB: %w", err)
+	}
+
+	existingCircles, err := r.findSearchCircles(ctx, bson.M{
+		"category": category,
+		"query":    nearbySearch,
+		"$and":     []bson.M{richEnough},
+		"location": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{bson.A{circle.Lng, circle.Lat}, (circle.Radius + largest.Radius) / domain.EarthRadius},
+		}},
+	}, options.Find())
+	if err != nil {
+		return false, err
+	}
+
+	covered := domain.ScannedFraction(circle, boundary, existingCircles, fieldLevel)
+	log.Printf("Area covered by %d overlapping circles: %.2f%%", len(existingCircles), covered*100)
+	return covered >= 1, nil
+}
+
+// EnsureIndexes backfills the GeoJSON location of search results saved before it
+// existed and creates the indexes used to find overlapping circles
+func (r *PlacesRepo) EnsureIndexes(ctx context.Context) error {
+	// Documents without numeric coordinates would get a malformed point and break the 2dsphere index
+	backfill := bson.M{
+		"location": bson.M{"$exists": false},
+		"lat":      bson.M{"$type": "number"},
+		"lng":      bson.M{"$type": "number"},
+	}
+	_, err := r.placesCollection.UpdateMany(ctx, backfill, mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"location": bson.M{"type": "Point", "coordinates": bson.A{"$lng", "$lat"}}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("error backfilling search result locations in MongoDB: %w", err)
+	}
+
+	_, err = r.placesCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "location", Value: "2dsphere"}, {Key: "category", Value: 1}}},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "radius", Value: -1}}},
+		{Keys: bson.D{{Key: "fetchedAt", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating search results indexes in MongoDB: %w", err)
+	}
+	return nil
+}
+
+// geoPoint is a GeoJSON point, which stores coordinates as [lng, lat]
+func geoPoint(lat, lng float64) bson.M {
+	return bson.M{"type": "Point", "coordinates": bson.A{lng, lat}}
+}
\ No newline at end of file
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"wheretoeat/internal/core/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type DishesRepo struct {
+	db *sqlx.DB
+}
+
+func NewDishesRepo(db *sqlx.DB) *DishesRepo {
+	return &DishesRepo{db: db}
+}
+
+// SavePlaceDishes replaces the dish mentions of a place
+func (r *DishesRepo) SavePlaceDishes(ctx context.Context, placeID string, dishes []domain.PlaceDish) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM place_dishes WHERE place_id = $1`, placeID)
+	if err != nil {
+		return fmt.Errorf("failed to delete place dishes: %w", err)
+	}
+
+	if len(dishes) > 0 {
+		query := `
+			INSERT INTO place_dishes (place_id, dish, mention_count, in_name)
+			VALUES (:place_id, :dish, :mention_count, :in_name)`
+		_, err = tx.NamedExecContext(ctx, query, dishes)
+		if err != nil {
+			return fmt.Errorf("failed to insert place dishes: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -1,241 +1,609 @@
-package postgres
-
-import (
-	"context"
-	"fmt"
-
-	"wheretoeat/internal/core/domain"
-	"github.com/jmoiron/sqlx"
-)
-
-type PlacesRepo struct {
-	db *sqlx.DB
-}
-
-func NewPlacesRepo(db *sqlx.DB) *PlacesRepo {
-	return &PlacesRepo{db: db}
-}
-
-func (r *PlacesRepo) SavePlace(ctx context.Context, place domain.Place) error {
-	query := `
-		INSERT INTO places (
-			place_id, name, category, lat, lng, rating, icon_mask_base_uri, primary_type,
-			short_address, phone_number, international_phone, takeout, good_for_groups,
-			google_maps_uri, utc_offset_minutes, icon_background_color, live_music, restroom,
-			dine_in, serves_breakfast, formatted_address, location
-		) VALUES (
-			:place_id, :name, :category, :lat, :lng, :rating, :icon_mask_base_uri, :primary_type,
-			:short_address, :phone_number, :international_phone, :takeout, :good_for_groups,
-			:google_maps_uri, :utc_offset_minutes, :icon_background_color, :live_music, :restroom,
-			:dine_in, :serves_breakfast, :formatted_address, ST_SetSRID(ST_MakePoint(:lng, :lat), 4326)
-		) ON CONFLICT (place_id) DO NOTHING`
-	_, err := r.db.NamedExecContext(ctx, query, place)
-	if err != nil {
-		return fmt.Errorf("failed to insert place: %w", err)
-	}
-	return nil
-}
-
-func (r *PlacesRepo) SaveBatch(ctx context.Context, places []domain.Place, photos []domain.Photo, reviews []domain.Review, openingHours []struct {
-	PlaceID string
-	Type    string
-	Periods string
-}, placeTypes []struct {
-	PlaceID string
-	Type    string
-}) error {
-	tx, err := r.db.BeginTxx(ctx, nil) // Use BeginTxx to start a sqlx transaction
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	// Batch insert into places
-	if len(places) > 0 {
-		query := `
-			INSERT INTO places (
-				place_id, name, category, lat, lng, rating, icon_mask_base_uri, primary_type,
-				short_address, phone_number, international_phone, takeout, good_for_groups,
-				google_maps_uri, utc_offset_minutes, icon_background_color, live_music, restroom,
-				dine_in, serves_breakfast, formatted_address, user_rating_count, location 
-			) VALUES (
-				:place_id, :name, :category, :lat, :lng, :rating, :icon_mask_base_uri, :primary_type,
-				:short_address, :phone_number, :international_phone, :takeout, :good_for_groups,
-				:google_maps_uri, :utc_offset_minutes, :icon_background_color, :live_music, :restroom,
-				:dine_in, :serves_breakfast, :formatted_address, :user_rating_count, ST_SetSRID(ST_MakePoint(:lng, :lat), 4326)
-			) ON CONFLICT (place_id) DO NOTHING`
-		_, err = tx.NamedExecContext(ctx, query, places)
-		if err != nil {
-			return fmt.Errorf("failed to batch insert places: %w", err)
-		}
-	}
-
-	// Batch insert into photos
-	if len(photos) > 0 {
-		// Validate photos data
-		query := `
-			INSERT INTO photos (place_id, flag_content_uri)
-			VALUES (:place_id, :flag_content_uri)
-			ON CONFLICT (flag_content_uri) DO NOTHING`
-		_, err = tx.NamedExecContext(ctx, query, photos)
-		if err != nil {
-			return fmt.Errorf("failed to batch insert photos: %w", err)
-		}
-	}
-
-	// Batch insert into opening_hours
-	if len(openingHours) > 0 {
-		query := `
-			INSERT INTO opening_hours (place_id, type, periods)
-			VALUES (:placeid, :type, :periods)
-			ON CONFLICT DO NOTHING`
-		_, err = tx.NamedExecContext(ctx, query, openingHours)
-		if err != nil {
-			return fmt.Errorf("failed to batch insert opening hours: %w", err)
-		}
-	}
-
-	// Batch insert into place_types
-	if len(placeTypes) > 0 {
-		query := `
-			INSERT INTO place_types (place_id, type)
-			VALUES (:placeid, :type)
-			ON CONFLICT DO NOTHING`
-		_, err = tx.NamedExecContext(ctx, query, placeTypes)
-		if err != nil {
-			return fmt.Errorf("failed to batch insert place types: %w", err)
-		}
-	}
-
-	return tx.Commit()
-}
-
-func (r *PlacesRepo) GetPhotos(ctx context.Context, limit, offset int) ([]domain.Photo, error) {
-
-	query := `SELECT * FROM photos LIMIT $1 OFFSET $2`
-	var photos []domain.Photo
-
-	err := r.db.SelectContext(ctx, &photos, query, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get photos: %w", err)
-	}
-
-	fmt.Printf("Fetched photos: %+v\n", photos)
-	return photos, nil
-}
-
-func (r *PlacesRepo) UpdatePhotoURL(ctx context.Context, imgUrl, placeID, uuid string) error {
-	query := `UPDATE photos SET img_url = $1 WHERE place_id = $2 AND photo_id = $3`
-	_, err := r.db.ExecContext(ctx, query, imgUrl, placeID, uuid)
-	if err != nil {
-		return fmt.Errorf("failed to update photo URL: %w", err)
-	}
-	return nil
-}
-
-func (r *PlacesRepo) GetNearbyPlaces(ctx context.Context, category string, circle domain.Circle, searchString string) ([]domain.Place, error) {
-	// Base query
-	placesQuery := `
-		SELECT place_id, name, lat, lng, rating, user_rating_count, primary_type,
-			phone_number, formatted_address, google_maps_uri,
-			CASE 
-				WHEN COALESCE($4, '') != '' THEN ts_rank(
-					to_tsvector('vietnamese', name) || to_tsvector('english', name), 
-					plainto_tsquery('vietnamese', $4) || plainto_tsquery('english', $4)
-				)
-				ELSE NULL
-			END AS search_rank
-		FROM places
-		WHERE ST_DWithin(
-				geography(ST_MakePoint(lng, lat)),
-				geography(ST_MakePoint($1, $2)),
-				$3
-			)
-			AND (user_rating_count > 100 OR (user_rating_count > 10 AND rating > 4.0))
-	`
-
-	args := []interface{}{circle.Lng, circle.Lat, circle.Radius, searchString}
-
-	// Handle category filter
-	if category != "" {
-		placesQuery += " AND category = $5"
-		args = append(args, category)
-	}
-
-	// ORDER BY - Prioritize search rank if searchString exists
-	placesQuery += `
-		ORDER BY 
-		CASE 
-			WHEN COALESCE($4, '') != '' THEN ts_rank(
-				to_tsvector('vietnamese', name) || to_tsvector('english', name), 
-				plainto_tsquery('vietnamese', $4) || plainto_tsquery('english', $4)
-			) 
-		END DESC NULLS LAST,
-		user_rating_count DESC,
-		ST_Distance(
-			geography(ST_MakePoint(lng, lat)),
-			geography(ST_MakePoint($1, $2))
-		) ASC
-		LIMIT 100
-	`
-
-	// Fetch places
-	var places []domain.Place
-	err := r.db.SelectContext(ctx, &places, placesQuery, args...)
-	if err != nil {
-		return nil, err
-	}
-
-	if len(places) == 0 {
-		return places, nil // No places found, return early
-	}
-
-	// Extract place IDs
-	placeIDs := make([]string, len(places))
-	placeMap := make(map[string]*domain.Place) // Map to aggregate photos
-	for i, p := range places {
-		placeIDs[i] = p.ID
-		placeMap[p.ID] = &places[i] // Reference for modification
-	}
-
-	var photos []domain.Photo
-	// Fetch photos for those places
-	query, args, err := sqlx.In("SELECT place_id, img_url FROM photos WHERE place_id IN (?)", placeIDs)
-	if err != nil {
-		return nil, err
-	}
-	query = r.db.Rebind(query) // Adjust SQL for PostgreSQL compatibility
-
-
-	err = r.db.SelectContext(ctx, &photos, query, args...)
-	if err != nil {
-		return nil, err
-	}
-
-	// Map photos to their respective places
-	for _, photo := range photos {
-		if place, exists := placeMap[photo.PlaceID]; exists {
-			//cannot use photo.ImageUrl (variable of type sql.NullString) as domain.Photo value in argument to append
-			place.PhotoUrls = append(place.PhotoUrls, photo.ImageUrl.String)
-		}
-	}
-
-	return places, nil
-}
-
-
-func (r *PlacesRepo) GetNumPlaces(ctx context.Context, category string, circle domain.Circle) (int64, error) {
-	// not implemented
-	return -1, nil
-}
-
-func (r *PlacesRepo) AreaHasBeenScanned(ctx context.Context, category string, circle domain.Circle) (bool, error) {
-	// not implemented
-	return false, nil
-}
-
-func (r *PlacesRepo) SaveSearchResults(ctx context.Context, category string, circle domain.Circle, places []interface{}) error {
-	// not implemented
-	return nil
-}
-
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"wheretoeat/internal/core/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// upsertReviewsQuery upserts reviews keyed on their resource name. The cast is spelled
+// out with CAST because sqlx would read "::" after a named parameter as an escaped colon.
+const upsertReviewsQuery = `
+	INSERT INTO reviews (
+		name, place_id, text, language_code, rating, author,
+		publish_time, relative_publish_time_description
+	) VALUES (
+		:name, :place_id, :text, :language_code, :rating, :author,
+		CAST(NULLIF(:publish_time, '') AS timestamptz), :relative_publish_time_description
+	) ON CONFLICT (name) DO UPDATE SET
+		text = EXCLUDED.text,
+		language_code = EXCLUDED.language_code,
+		rating = EXCLUDED.rating,
+		author = EXCLUDED.author,
+		publish_time = EXCLUDED.publish_time,
+		relative_publish_time_description = EXCLUDED.relative_publish_time_description`
+
+type PlacesRepo struct {
+	db *sqlx.DB
+}
+
+func NewPlacesRepo(db *sqlx.DB) *PlacesRepo {
+	return &PlacesRepo{db: db}
+}
+
+func (r *PlacesRepo) SavePlace(ctx context.Context, place domain.Place) error {
+	query := `
+		INSERT INTO places (
+			place_id, name, lat, lng, rating, icon_mask_base_uri, primary_type,
+			short_address, phone_number, international_phone, takeout, good_for_groups,
+			google_maps_uri, utc_offset_minutes, icon_background_color, live_music, restroom,
+			dine_in, serves_breakfast, formatted_address, price_level, provider, field_level, location
+		) VALUES (
+			:place_id, :name, :lat, :lng, :rating, :icon_mask_base_uri, :primary_type,
+			:short_address, :phone_number, :international_phone, :takeout, :good_for_groups,
+			:google_maps_uri, :utc_offset_minutes, :icon_background_color, :live_music, :restroom,
+			:dine_in, :serves_breakfast, :formatted_address, :price_level, :provider, :field_level, ST_SetSRID(ST_MakePoint(:lng, :lat), 4326)
+		) ON CONFLICT (place_id) DO NOTHING`
+	_, err := r.db.NamedExecContext(ctx, query, place)
+	if err != nil {
+		return fmt.Errorf("failed to insert place: %w", err)
+	}
+
+	if place.Category != "" {
+		_, err = r.db.ExecContext(ctx, `
+			INSERT INTO place_categories (place_id, category)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`, place.ID, place.Category)
+		if err != nil {
+			return fmt.Errorf("failed to insert place category: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *PlacesRepo) SaveBatch(ctx context.Context, places []domain.Place, photos []domain.Photo, reviews []domain.Review, openingHours []struct {
+	PlaceID string
+	Type    string
+	Periods string
+}, placeTypes []struct {
+	PlaceID string
+	Type    string
+}, placeCategories []struct {
+	PlaceID  string
+	Category string
+}) error {
+	tx, err := r.db.BeginTxx(ctx, nil) // Use BeginTxx to start a sqlx transaction
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveBatch(ctx, tx, places, photos, reviews, openingHours, placeTypes, placeCategories); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveBatch(ctx context.Context, tx *sqlx.Tx, places []domain.Place, photos []domain.Photo, reviews []domain.Review, openingHours []struct {
+	PlaceID string
+	Type    string
+	Periods string
+}, placeTypes []struct {
+	PlaceID string
+	Type    string
+}, placeCategories []struct {
+	PlaceID  string
+	Category string
+}) error {
+	var err error
+
+	// Batch upsert into places. Basic fields are in every field mask and always refreshed;
+	// richer fields are only replaced by data requested with at least their level, so a
+	// cheap crawl never blanks out what a richer one stored.
+	if len(places) > 0 {
+		query := `
+			INSERT INTO places (
+				place_id, name, lat, lng, rating, icon_mask_base_uri, primary_type,
+				short_address, phone_number, international_phone, takeout, good_for_groups,
+				google_maps_uri, utc_offset_minutes, icon_background_color, live_music, restroom,
+				dine_in, serves_breakfast, formatted_address, user_rating_count, price_level, provider, field_level,
+				business_status, fetched_at, city, district, ward, location
+			) VALUES (
+				:place_id, :name, :lat, :lng, :rating, :icon_mask_base_uri, :primary_type,
+				:short_address, :phone_number, :international_phone, :takeout, :good_for_groups,
+				:google_maps_uri, :utc_offset_minutes, :icon_background_color, :live_music, :restroom,
+				:dine_in, :serves_breakfast, :formatted_address, :user_rating_count, :price_level, :provider, :field_level,
+				:business_status, :fetched_at, NULLIF(:city, ''), NULLIF(:district, ''), NULLIF(:ward, ''),
+				ST_SetSRID(ST_MakePoint(:lng, :lat), 4326)
+			) ON CONFLICT (place_id) DO UPDATE SET
+				name = EXCLUDED.name,
+				lat = EXCLUDED.lat,
+				lng = EXCLUDED.lng,
+				location = EXCLUDED.location,
+				icon_mask_base_uri = EXCLUDED.icon_mask_base_uri,
+				icon_background_color = EXCLUDED.icon_background_color,
+				primary_type = EXCLUDED.primary_type,
+				short_address = EXCLUDED.short_address,
+				formatted_address = EXCLUDED.formatted_address,
+				google_maps_uri = EXCLUDED.google_maps_uri,
+				utc_offset_minutes = EXCLUDED.utc_offset_minutes,
+				business_status = EXCLUDED.business_status,
+				fetched_at = GREATEST(places.fetched_at, EXCLUDED.fetched_at),
+				city = COALESCE(EXCLUDED.city, places.city),
+				district = COALESCE(EXCLUDED.district, places.district),
+				ward = COALESCE(EXCLUDED.ward, places.ward),
+				rating = CASE WHEN EXCLUDED.field_level >= 1 THEN EXCLUDED.rating ELSE places.rating END,
+				user_rating_count = CASE WHEN EXCLUDED.field_level >= 1 THEN EXCLUDED.user_rating_count ELSE places.user_rating_count END,
+				price_level = CASE WHEN EXCLUDED.field_level >= 1 THEN EXCLUDED.price_level ELSE places.price_level END,
+				phone_number = CASE WHEN EXCLUDED.field_level >= 1 THEN EXCLUDED.phone_number ELSE places.phone_number END,
+				international_phone = CASE WHEN EXCLUDED.field_level >= 1 THEN EXCLUDED.international_phone ELSE places.international_phone END,
+				takeout = CASE WHEN EXCLUDED.field_level >= 2 THEN EXCLUDED.takeout ELSE places.takeout END,
+				good_for_groups = CASE WHEN EXCLUDED.field_level >= 2 THEN EXCLUDED.good_for_groups ELSE places.good_for_groups END,
+				live_music = CASE WHEN EXCLUDED.field_level >= 2 THEN EXCLUDED.live_music ELSE places.live_music END,
+				restroom = CASE WHEN EXCLUDED.field_level >= 2 THEN EXCLUDED.restroom ELSE places.restroom END,
+				dine_in = CASE WHEN EXCLUDED.field_level >= 2 THEN EXCLUDED.dine_in ELSE places.dine_in END,
+				serves_breakfast = CASE WHEN EXCLUDED.field_level >= 2 THEN EXCLUDED.serves_breakfast ELSE places.serves_breakfast END,
+				field_level = GREATEST(places.field_level, EXCLUDED.field_level)`
+		_, err = tx.NamedExecContext(ctx, query, places)
+		if err != nil {
+			return fmt.Errorf("failed to batch insert places: %w", err)
+		}
+	}
+
+	// Batch insert into photos
+	if len(photos) > 0 {
+		// Validate photos data
+		query := `
+			INSERT INTO photos (place_id, flag_content_uri)
+			VALUES (:place_id, :flag_content_uri)
+			ON CONFLICT (flag_content_uri) DO NOTHING`
+		_, err = tx.NamedExecContext(ctx, query, photos)
+		if err != nil {
+			return fmt.Errorf("failed to batch insert photos: %w", err)
+		}
+	}
+
+	// Batch upsert into reviews, keyed on the review resource name
+	if len(reviews) > 0 {
+		_, err = tx.NamedExecContext(ctx, upsertReviewsQuery, reviews)
+		if err != nil {
+			return fmt.Errorf("failed to batch insert reviews: %w", err)
+		}
+	}
+
+	// Opening hours and types are replaced rather than merged. Hours are only requested
+	// from the contact level up, so a cheaper crawl keeps the ones already stored.
+	var typesIDs, hoursIDs []string
+	replaceHours := make(map[string]bool)
+	for _, place := range places {
+		typesIDs = append(typesIDs, place.ID)
+		if place.FieldLevel >= domain.FieldLevelContact {
+			replaceHours[place.ID] = true
+		}
+	}
+	for _, hours := range openingHours {
+		replaceHours[hours.PlaceID] = true
+	}
+	for placeID := range replaceHours {
+		hoursIDs = append(hoursIDs, placeID)
+	}
+	if len(hoursIDs) > 0 {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM opening_hours WHERE place_id = ANY($1)", pq.Array(hoursIDs)); err != nil {
+			return fmt.Errorf("failed to delete opening hours: %w", err)
+		}
+	}
+	if len(typesIDs) > 0 {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM place_types WHERE place_id = ANY($1)", pq.Array(typesIDs)); err != nil {
+			return fmt.Errorf("failed to delete place types: %w", err)
+		}
+	}
+
+	// Batch insert into opening_hours
+	if len(openingHours) > 0 {
+		query := `
+			INSERT INTO opening_hours (place_id, type, periods)
+			VALUES (:placeid, :type, :periods)
+			ON CONFLICT DO NOTHING`
+		_, err = tx.NamedExecContext(ctx, query, openingHours)
+		if err != nil {
+			return fmt.Errorf("failed to batch insert opening hours: %w", err)
+		}
+	}
+
+	// Batch insert into place_types
+	if len(placeTypes) > 0 {
+		query := `
+			INSERT INTO place_types (place_id, type)
+			VALUES (:placeid, :type)
+			ON CONFLICT DO NOTHING`
+		_, err = tx.NamedExecContext(ctx, query, placeTypes)
+		if err != nil {
+			return fmt.Errorf("failed to batch insert place types: %w", err)
+		}
+	}
+
+	// Batch insert into place_categories. A place found under several
+	// categories gets one row per category.
+	if len(placeCategories) > 0 {
+		query := `
+			INSERT INTO place_categories (place_id, category)
+			VALUES (:placeid, :category)
+			ON CONFLICT DO NOTHING`
+		_, err = tx.NamedExecContext(ctx, query, placeCategories)
+		if err != nil {
+			return fmt.Errorf("failed to batch insert place categories: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpsertPlaceDetails writes a single place fetched from Place Details, replacing its
+// types and, if they were requested, its opening hours. Photos and reviews are added
+// the same way the ETL adds them.
+func (r *PlacesRepo) UpsertPlaceDetails(ctx context.Context, place domain.Place) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var photos []domain.Photo
+	for _, photo := range place.Photos {
+		photo.PlaceID = place.ID
+		photos = append(photos, photo)
+	}
+	var reviews []domain.Review
+	for _, review := range place.Reviews {
+		if review.Name != "" {
+			reviews = append(reviews, review.ToRow(place.ID))
+		}
+	}
+
+	var openingHours []struct {
+		PlaceID string
+		Type    string
+		Periods string
+	}
+	if place.FieldLevel >= domain.FieldLevelContact {
+		hoursTypes := []string{"regular", "current"}
+		for i, hours := range []*domain.OpeningHours{place.OpeningHours, place.CurrentOpeningHours} {
+			if hours == nil {
+				continue
+			}
+			periodsJSON, err := json.Marshal(hours.Periods)
+			if err != nil {
+				return fmt.Errorf("failed to serialize %s opening hours: %w", hoursTypes[i], err)
+			}
+			openingHours = append(openingHours, struct {
+				PlaceID string
+				Type    string
+				Periods string
+			}{place.ID, hoursTypes[i], string(periodsJSON)})
+		}
+	}
+
+	var placeTypes []struct {
+		PlaceID string
+		Type    string
+	}
+	for _, t := range place.Types {
+		placeTypes = append(placeTypes, struct {
+			PlaceID string
+			Type    string
+		}{place.ID, t})
+	}
+
+	if err := saveBatch(ctx, tx, []domain.Place{place}, photos, reviews, openingHours, placeTypes, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecordPlaceView counts one view of a place's detail page
+func (r *PlacesRepo) RecordPlaceView(ctx context.Context, placeID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO place_views (place_id, view_count, last_viewed_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (place_id) DO UPDATE SET
+			view_count = place_views.view_count + 1,
+			last_viewed_at = NOW()`, placeID)
+	if err != nil {
+		return fmt.Errorf("failed to record place view: %w", err)
+	}
+	return nil
+}
+
+// GetMostViewedPlaceIDs returns the IDs of the limit most viewed Google places
+func (r *PlacesRepo) GetMostViewedPlaceIDs(ctx context.Context, limit int) ([]string, error) {
+	var placeIDs []string
+	err := r.db.SelectContext(ctx, &placeIDs, `
+		SELECT v.place_id
+		FROM place_views v
+		JOIN places p ON p.place_id = v.place_id
+		WHERE p.provider = $1
+		ORDER BY v.view_count DESC, v.last_viewed_at DESC
+		LIMIT $2`, domain.ProviderGoogle, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most viewed places: %w", err)
+	}
+	return placeIDs, nil
+}
+
+func (r *PlacesRepo) GetPhotos(ctx context.Context, limit, offset int) ([]domain.Photo, error) {
+
+	query := `SELECT * FROM photos LIMIT $1 OFFSET $2`
+	var photos []domain.Photo
+
+	err := r.db.SelectContext(ctx, &photos, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photos: %w", err)
+	}
+
+	fmt.Printf("Fetched photos: %+v\n", photos)
+	return photos, nil
+}
+
+func (r *PlacesRepo) UpdatePhotoURL(ctx context.Context, imgUrl, placeID, uuid string) error {
+	query := `UPDATE photos SET img_url = $1 WHERE place_id = $2 AND photo_id = $3`
+	_, err := r.db.ExecContext(ctx, query, imgUrl, placeID, uuid)
+	if err != nil {
+		return fmt.Errorf("failed to update photo URL: %w", err)
+	}
+	return nil
+}
+
+func (r *PlacesRepo) GetNearbyPlaces(ctx context.Context, category, district string, circle domain.Circle, searchString string, dishes []string) ([]domain.Place, error) {
+	// Base query. The search rank combines the full text match on the name with
+	// the dishes the search string refers to: a dish in the place name weighs 1.0,
+	// review mentions add up to 0.5 (saturating at 20 mentions).
+	placesQuery := `
+		SELECT place_id, name, lat, lng, rating, user_rating_count, primary_type,
+			phone_number, formatted_address, google_maps_uri,
+			COALESCE(city, '') AS city, COALESCE(district, '') AS district, COALESCE(ward, '') AS ward,
+			CASE 
+				WHEN COALESCE($4, '') != '' THEN ts_rank(
+					to_tsvector('vietnamese', name) || to_tsvector('english', name), 
+					plainto_tsquery('vietnamese', $4) || plainto_tsquery('english', $4)
+				) + COALESCE(dish_match.score, 0)
+				ELSE 0
+			END AS search_rank
+		FROM places
+		LEFT JOIN LATERAL (
+			SELECT SUM(
+				CASE WHEN pd.in_name THEN 1.0 ELSE 0.0 END
+				+ 0.5 * LEAST(pd.mention_count, 20) / 20.0
+			) AS score
+			FROM place_dishes pd
+			WHERE pd.place_id = places.place_id AND pd.dish = ANY($5)
+		) dish_match ON TRUE
+		WHERE ST_DWithin(
+				geography(ST_MakePoint(lng, lat)),
+				geography(ST_MakePoint($1, $2)),
+				$3
+			)
+			AND (provider = $6 OR user_rating_count > 100 OR (user_rating_count > 10 AND rating > 4.0))
+	`
+
+	// OpenStreetMap has no ratings, so its places are exempt from the rating filter
+	args := []interface{}{circle.Lng, circle.Lat, circle.Radius, searchString, pq.Array(dishes), domain.ProviderOSM}
+
+	// Handle category filter
+	if category != "" {
+		args = append(args, category)
+		placesQuery += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM place_categories pc WHERE pc.place_id = places.place_id AND pc.category = $%d)", len(args))
+	}
+
+	// Handle district filter, names as imported with run-import-areas
+	if district != "" {
+		args = append(args, district)
+		placesQuery += fmt.Sprintf(" AND lower(places.district) = lower($%d)", len(args))
+	}
+
+	// ORDER BY - Prioritize search rank if searchString exists
+	placesQuery += `
+		ORDER BY 
+		search_rank DESC,
+		user_rating_count DESC,
+		ST_Distance(
+			geography(ST_MakePoint(lng, lat)),
+			geography(ST_MakePoint($1, $2))
+		) ASC
+		LIMIT 100
+	`
+
+	// Fetch places
+	var places []domain.Place
+	err := r.db.SelectContext(ctx, &places, placesQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(places) == 0 {
+		return places, nil // No places found, return early
+	}
+
+	// Extract place IDs
+	placeIDs := make([]string, len(places))
+	placeMap := make(map[string]*domain.Place) // Map to aggregate photos
+	for i, p := range places {
+		placeIDs[i] = p.ID
+		placeMap[p.ID] = &places[i] // Reference for modification
+	}
+
+	var photos []domain.Photo
+	// Fetch photos for those places
+	query, args, err := sqlx.In("SELECT place_id, img_url FROM photos WHERE place_id IN (?)", placeIDs)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query) // Adjust SQL for PostgreSQL compatibility
+
+
+	err = r.db.SelectContext(ctx, &photos, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Map photos to their respective places
+	for _, photo := range photos {
+		if place, exists := placeMap[photo.PlaceID]; exists {
+			//cannot use photo.ImageUrl (variable of type sql.NullString) as domain.Photo value in argument to append
+			place.PhotoUrls = append(place.PhotoUrls, photo.ImageUrl.String)
+		}
+	}
+
+	return places, nil
+}
+
+
+func (r *PlacesRepo) ListPlaces(ctx context.Context, limit, offset int) ([]domain.Place, error) {
+	query := `SELECT place_id, name FROM places ORDER BY place_id LIMIT $1 OFFSET $2`
+	var places []domain.Place
+
+	err := r.db.SelectContext(ctx, &places, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list places: %w", err)
+	}
+	return places, nil
+}
+
+// PlaceExists reports whether a place is stored, without loading it
+func (r *PlacesRepo) PlaceExists(ctx context.Context, placeID string) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM places WHERE place_id = $1)`, placeID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check place: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *PlacesRepo) GetPlace(ctx context.Context, placeID string) (*domain.Place, error) {
+	query := `
+		SELECT place_id, name, lat, lng, rating, user_rating_count, primary_type,
+			phone_number, formatted_address, google_maps_uri, provider,
+			COALESCE(business_status, '') AS business_status, fetched_at,
+			COALESCE(city, '') AS city, COALESCE(district, '') AS district, COALESCE(ward, '') AS ward
+		FROM places
+		WHERE place_id = $1`
+	var place domain.Place
+	err := r.db.GetContext(ctx, &place, query, placeID)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found, return nil instead of error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get place: %w", err)
+	}
+
+	var photos []domain.Photo
+	err = r.db.SelectContext(ctx, &photos, "SELECT place_id, img_url FROM photos WHERE place_id = $1", placeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photos: %w", err)
+	}
+	for _, photo := range photos {
+		place.PhotoUrls = append(place.PhotoUrls, photo.ImageUrl.String)
+	}
+
+	err = r.db.SelectContext(ctx, &place.Categories, "SELECT category FROM place_categories WHERE place_id = $1 ORDER BY category", placeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get place categories: %w", err)
+	}
+
+	return &place, nil
+}
+
+// placeFeaturesQuery selects a place with its types, dishes and review keywords,
+// plus its distance to the point ($1 = lng, $2 = lat). Summaries saved with JSON null
+// instead of an array contribute no keywords.
+const placeFeaturesQuery = `
+	SELECT p.place_id, p.name, p.lat, p.lng, COALESCE(p.rating, 0) AS rating,
+		COALESCE(p.user_rating_count, 0) AS user_rating_count, COALESCE(p.primary_type, '') AS primary_type,
+		COALESCE(p.price_level, '') AS price_level, COALESCE(p.phone_number, '') AS phone_number,
+		COALESCE(p.formatted_address, '') AS formatted_address, COALESCE(p.google_maps_uri, '') AS google_maps_uri,
+		ARRAY(SELECT t.type FROM place_types t WHERE t.place_id = p.place_id) AS type_list,
+		ARRAY(SELECT d.dish FROM place_dishes d WHERE d.place_id = p.place_id) AS dish_list,
+		ARRAY(
+			SELECT jsonb_array_elements_text(CASE WHEN jsonb_typeof(s.highlights) = 'array' THEN s.highlights ELSE '[]' END)
+			FROM place_review_summaries s WHERE s.place_id = p.place_id
+		) || ARRAY(
+			SELECT e->>'Phrase'
+			FROM place_review_summaries s,
+				jsonb_array_elements(CASE WHEN jsonb_typeof(s.top_phrases) = 'array' THEN s.top_phrases ELSE '[]' END) e
+			WHERE s.place_id = p.place_id
+		) AS keyword_list,
+		ST_Distance(geography(ST_MakePoint(p.lng, p.lat)), geography(ST_MakePoint($1, $2))) AS distance
+	FROM places p`
+
+type placeFeaturesRow struct {
+	domain.Place
+	TypeList    pq.StringArray `db:"type_list"`
+	DishList    pq.StringArray `db:"dish_list"`
+	KeywordList pq.StringArray `db:"keyword_list"`
+	Distance    float64        `db:"distance"`
+}
+
+func (row placeFeaturesRow) toDomain() domain.PlaceFeatures {
+	return domain.PlaceFeatures{
+		Place:          row.Place,
+		Types:          row.TypeList,
+		Dishes:         row.DishList,
+		Keywords:       row.KeywordList,
+		DistanceMeters: row.Distance,
+	}
+}
+
+func (r *PlacesRepo) GetPlaceFeatures(ctx context.Context, placeID string) (*domain.PlaceFeatures, error) {
+	var row placeFeaturesRow
+	query := placeFeaturesQuery + ` WHERE p.place_id = $3`
+	err := r.db.GetContext(ctx, &row, query, 0.0, 0.0, placeID)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not found, return nil instead of error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get place features: %w", err)
+	}
+	features := row.toDomain()
+	features.DistanceMeters = 0
+	return &features, nil
+}
+
+// GetNearbyPlaceFeatures returns the features of the places within circle, nearest first.
+// Closed places are left out since they are never worth suggesting.
+func (r *PlacesRepo) GetNearbyPlaceFeatures(ctx context.Context, circle domain.Circle, limit int) ([]domain.PlaceFeatures, error) {
+	var rows []placeFeaturesRow
+	query := placeFeaturesQuery + `
+		WHERE ST_DWithin(geography(ST_MakePoint(p.lng, p.lat)), geography(ST_MakePoint($1, $2)), $3)
+			AND COALESCE(p.business_status, '') NOT IN ('CLOSED_PERMANENTLY', 'CLOSED_TEMPORARILY')
+		ORDER BY distance ASC
+		LIMIT $4`
+	err := r.db.SelectContext(ctx, &rows, query, circle.Lng, circle.Lat, circle.Radius, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nearby place features: %w", err)
+	}
+
+	features := make([]domain.PlaceFeatures, len(rows))
+	for i, row := range rows {
+		features[i] = row.toDomain()
+	}
+	return features, nil
+}
+
+func (r *PlacesRepo) GetNumPlaces(ctx context.Context, category string, circle domain.Circle) (int64, error) {
+	// not implemented
+	return -1, nil
+}
+
+func (r *PlacesRepo) AreaHasBeenScanned(ctx context.Context, category string, circle domain.Circle, boundary domain.Boundary, fieldLevel int) (bool, error) {
+	// not implemented
+	return false, nil
+}
+
+func (r *PlacesRepo) SaveSearchResults(ctx context.Context, category string, circle domain.Circle, fieldMask domain.FieldMask, places []domain.PlaceResult) error {
+	// not implemented
+	return nil
+}
\ No newline at end of file
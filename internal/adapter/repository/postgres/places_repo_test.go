@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"wheretoeat/internal/core/domain"
+)
+
+func TestUpsertReviewsQueryBinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		reviews  []domain.Review
+		wantCast []string
+	}{
+		{
+			name:     "one review",
+			reviews:  []domain.Review{{Name: "places/a/reviews/1", PublishTime: "2024-05-01T10:00:00Z"}},
+			wantCast: []string{"CAST(NULLIF($7, '') AS timestamptz)"},
+		},
+		{
+			name: "batch of reviews",
+			reviews: []domain.Review{
+				{Name: "places/a/reviews/1", PublishTime: "2024-05-01T10:00:00Z"},
+				{Name: "places/a/reviews/2"},
+			},
+			wantCast: []string{"CAST(NULLIF($7, '') AS timestamptz)", "CAST(NULLIF($15, '') AS timestamptz)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := sqlx.Named(upsertReviewsQuery, tt.reviews)
+			if err != nil {
+				t.Fatalf("binding reviews: %v", err)
+			}
+			query = sqlx.Rebind(sqlx.DOLLAR, query)
+
+			if strings.Contains(query, ":timestamptz") {
+				t.Errorf("query casts with a single colon, which Postgres rejects:\n%s", query)
+			}
+			for _, cast := range tt.wantCast {
+				if !strings.Contains(query, cast) {
+					t.Errorf("query lacks %s:\n%s", cast, query)
+				}
+			}
+			if want := 8 * len(tt.reviews); len(args) != want {
+				t.Errorf("got %d args, want %d", len(args), want)
+			}
+		})
+	}
+}
\ No newline at end of file
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"wheretoeat/internal/core/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type ReviewsRepo struct {
+	db *sqlx.DB
+}
+
+func NewReviewsRepo(db *sqlx.DB) *ReviewsRepo {
+	return &ReviewsRepo{db: db}
+}
+
+func (r *ReviewsRepo) GetReviews(ctx context.Context, placeID string) ([]domain.Review, error) {
+	query := `
+		SELECT name, place_id, COALESCE(text, '') AS text, COALESCE(language_code, '') AS language_code,
+			COALESCE(rating, 0) AS rating, COALESCE(author, '') AS author,
+			COALESCE(to_char(publish_time AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'), '') AS publish_time,
+			COALESCE(relative_publish_time_description, '') AS relative_publish_time_description
+		FROM reviews
+		WHERE place_id = $1
+		ORDER BY publish_time DESC NULLS LAST`
+	reviews := []domain.Review{} // Encodes as [] rather than null when there are none
+
+	err := r.db.SelectContext(ctx, &reviews, query, placeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+func (r *ReviewsRepo) GetReviewedPlaceIDs(ctx context.Context) ([]string, error) {
+	var placeIDs []string
+	err := r.db.SelectContext(ctx, &placeIDs, `SELECT DISTINCT place_id FROM reviews WHERE place_id IS NOT NULL ORDER BY place_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviewed place ids: %w", err)
+	}
+	return placeIDs, nil
+}
+
+func (r *ReviewsRepo) SaveReviewSummary(ctx context.Context, summary domain.ReviewSummary) error {
+	// Store empty arrays rather than JSON null so the columns can always be unnested
+	if summary.Aspects == nil {
+		summary.Aspects = []domain.AspectSentiment{}
+	}
+	if summary.TopPhrases == nil {
+		summary.TopPhrases = []domain.PhraseCount{}
+	}
+	if summary.Highlights == nil {
+		summary.Highlights = []string{}
+	}
+
+	aspectsJSON, err := json.Marshal(summary.Aspects)
+	if err != nil {
+		return fmt.Errorf("failed to serialize aspects: %w", err)
+	}
+	phrasesJSON, err := json.Marshal(summary.TopPhrases)
+	if err != nil {
+		return fmt.Errorf("failed to serialize top phrases: %w", err)
+	}
+	highlightsJSON, err := json.Marshal(summary.Highlights)
+	if err != nil {
+		return fmt.Errorf("failed to serialize highlights: %w", err)
+	}
+
+	query := `
+		INSERT INTO place_review_summaries (place_id, review_count, aspects, top_phrases, highlights, analyzed_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (place_id) DO UPDATE SET
+			review_count = EXCLUDED.review_count,
+			aspects = EXCLUDED.aspects,
+			top_phrases = EXCLUDED.top_phrases,
+			highlights = EXCLUDED.highlights,
+			analyzed_at = EXCLUDED.analyzed_at`
+	_, err = r.db.ExecContext(ctx, query, summary.PlaceID, summary.ReviewCount,
+		string(aspectsJSON), string(phrasesJSON), string(highlightsJSON))
+	if err != nil {
+		return fmt.Errorf("failed to save review summary: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewsRepo) GetReviewSummary(ctx context.Context, placeID string) (*domain.ReviewSummary, error) {
+	var row struct {
+		domain.ReviewSummary
+		AspectsJSON    string `db:"aspects"`
+		PhrasesJSON    string `db:"top_phrases"`
+		HighlightsJSON string `db:"highlights"`
+	}
+	query := `
+		SELECT place_id, review_count, aspects, top_phrases, highlights, analyzed_at
+		FROM place_review_summaries
+		WHERE place_id = $1`
+	err := r.db.GetContext(ctx, &row, query, placeID)
+	if err == sql.ErrNoRows {
+		return nil, nil // Not analyzed yet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review summary: %w", err)
+	}
+
+	summary := row.ReviewSummary
+	if err := json.Unmarshal([]byte(row.AspectsJSON), &summary.Aspects); err != nil {
+		return nil, fmt.Errorf("failed to parse aspects: %w", err)
+	}
+	if err := json.Unmarshal([]byte(row.PhrasesJSON), &summary.TopPhrases); err != nil {
+		return nil, fmt.Errorf("failed to parse top phrases: %w", err)
+	}
+	if err := json.Unmarshal([]byte(row.HighlightsJSON), &summary.Highlights); err != nil {
+		return nil, fmt.Errorf("failed to parse highlights: %w", err)
+	}
+	return &summary, nil
+}
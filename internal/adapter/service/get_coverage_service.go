@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+type GetCoverageService struct {
+	searchResultsRepo port.SearchResultsRepository
+	areasRepo         port.AreasRepository
+}
+
+func NewGetCoverageService(searchResultsRepo port.SearchResultsRepository, areasRepo port.AreasRepository) *GetCoverageService {
+	return &GetCoverageService{searchResultsRepo: searchResultsRepo, areasRepo: areasRepo}
+}
+
+// GetCoverage returns every stored search circle of the category and how much of each
+// stored area they cover, or of the named area only. It returns nil if the area doesn't exist.
+func (s *GetCoverageService) GetCoverage(ctx context.Context, category, areaName string) (*domain.CoverageReport, error) {
+	var areas []domain.Area
+	if areaName != "" {
+		area, err := s.areasRepo.GetAreaByName(ctx, areaName)
+		if err != nil || area == nil {
+			return nil, err
+		}
+		areas = []domain.Area{*area}
+	} else {
+		var err error
+		areas, err = s.areasRepo.ListAreas(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	circles, err := s.searchResultsRepo.GetSearchCircles(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get search circles of %s: %w", category, err)
+	}
+
+	report := &domain.CoverageReport{Category: category, Areas: make([]domain.AreaCoverage, 0, len(areas))}
+	for _, area := range areas {
+		report.Areas = append(report.Areas, domain.MeasureAreaCoverage(area, circles))
+	}
+
+	// A single area only shows the circles reaching into it
+	if areaName != "" {
+		outline := areas[0].Outline()
+		for _, c := range circles {
+			if outline.IntersectsCircle(c.Circle) {
+				report.Circles = append(report.Circles, c)
+			}
+		}
+	} else {
+		report.Circles = circles
+	}
+	return report, nil
+}
\ No newline at end of file
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+type GetPlaceService struct {
+	placesRepo  port.PlacesRepository
+	reviewsRepo port.ReviewsRepository
+}
+
+func NewGetPlaceService(placesRepo port.PlacesRepository, reviewsRepo port.ReviewsRepository) *GetPlaceService {
+	return &GetPlaceService{placesRepo: placesRepo, reviewsRepo: reviewsRepo}
+}
+
+// GetPlace returns a place with its review summary attached, or nil if it doesn't exist
+func (s *GetPlaceService) GetPlace(ctx context.Context, placeID string) (*domain.Place, error) {
+	place, err := s.placesRepo.GetPlace(ctx, placeID)
+	if err != nil || place == nil {
+		return nil, err
+	}
+
+	// Views pick the places worth refreshing first, losing one is harmless
+	if err := s.placesRepo.RecordPlaceView(ctx, placeID); err != nil {
+		log.Printf("Failed to record view of place %s: %v", placeID, err)
+	}
+
+	summary, err := s.reviewsRepo.GetReviewSummary(ctx, placeID)
+	if err != nil {
+		return nil, err
+	}
+	place.ReviewSummary = summary
+	return place, nil
+}
@@ -1,30 +1,36 @@
-package service
-
-import (
-	"context"
-	"wheretoeat/internal/core/domain"
-	"wheretoeat/internal/core/port"
-)
-
-type GetPlacesService struct {
-	placesRepo port.PlacesRepository
-}
-
-func NewGetPlacesService(placesRepo port.PlacesRepository) *GetPlacesService {
-	return &GetPlacesService{placesRepo: placesRepo}
-}
-
-func (s *GetPlacesService) GetNearbyPlaces(ctx context.Context, lat, lng, radius float64, category string, searchString string) ([]domain.Place, error) {
-	// construct the circle from the lat, lng and radius
-	circle := domain.Circle{
-		Lat:    lat,
-		Lng:    lng,
-		Radius: radius,
-	}
-	// call to repository to get the places
-	places, err := s.placesRepo.GetNearbyPlaces(ctx, category, circle, searchString)
-	if err != nil {
-		return nil, err
-	}	
-	return places, nil
-}
\ No newline at end of file
+package service
+
+import (
+	"context"
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+type GetPlacesService struct {
+	placesRepo    port.PlacesRepository
+	dishExtractor port.DishExtractor
+}
+
+func NewGetPlacesService(placesRepo port.PlacesRepository, dishExtractor port.DishExtractor) *GetPlacesService {
+	return &GetPlacesService{placesRepo: placesRepo, dishExtractor: dishExtractor}
+}
+
+func (s *GetPlacesService) GetNearbyPlaces(ctx context.Context, lat, lng, radius float64, category, district string, searchString string) ([]domain.Place, error) {
+	// construct the circle from the lat, lng and radius
+	circle := domain.Circle{
+		Lat:    lat,
+		Lng:    lng,
+		Radius: radius,
+	}
+	// resolve the dishes the search string refers to, e.g. "bun bo" -> "bún bò"
+	dishes := []string{}
+	for dish := range s.dishExtractor.ExtractDishes(searchString) {
+		dishes = append(dishes, dish)
+	}
+	// call to repository to get the places
+	places, err := s.placesRepo.GetNearbyPlaces(ctx, category, district, circle, searchString, dishes)
+	if err != nil {
+		return nil, err
+	}	
+	return places, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+type GetReviewsService struct {
+	placesRepo  port.PlacesRepository
+	reviewsRepo port.ReviewsRepository
+}
+
+func NewGetReviewsService(placesRepo port.PlacesRepository, reviewsRepo port.ReviewsRepository) *GetReviewsService {
+	return &GetReviewsService{placesRepo: placesRepo, reviewsRepo: reviewsRepo}
+}
+
+// GetReviews returns the reviews of a place, newest first, or nil if the place doesn't exist
+func (s *GetReviewsService) GetReviews(ctx context.Context, placeID string) ([]domain.Review, error) {
+	exists, err := s.placesRepo.PlaceExists(ctx, placeID)
+	if err != nil || !exists {
+		return nil, err
+	}
+	reviews, err := s.reviewsRepo.GetReviews(ctx, placeID)
+	if err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"wheretoeat/internal/core/domain"
+	"wheretoeat/internal/core/port"
+)
+
+const (
+	maxSimilarCandidates = 300 // Nearby places scored per request
+
+	// Weights of each signal in the similarity score, summing to 1
+	typesWeight       = 0.30
+	primaryTypeWeight = 0.20
+	priceLevelWeight  = 0.15
+	ratingBandWeight  = 0.10
+	dishesWeight      = 0.15
+	keywordsWeight    = 0.10
+
+	distancePenalty = 0.2 // Share of the score lost by a candidate right at the distance cap
+)
+
+// genericTypes are shared by almost every place and say nothing about similarity
+var genericTypes = map[string]bool{
+	"food": true, "point_of_interest": true, "establishment": true, "store": true,
+}
+
+var priceLevels = map[string]int{
+	"PRICE_LEVEL_FREE":           0,
+	"PRICE_LEVEL_INEXPENSIVE":    1,
+	"PRICE_LEVEL_MODERATE":       2,
+	"PRICE_LEVEL_EXPENSIVE":      3,
+	"PRICE_LEVEL_VERY_EXPENSIVE": 4,
+}
+
+type GetSimilarPlacesService struct {
+	placesRepo port.PlacesRepository
+}
+
+func NewGetSimilarPlacesService(placesRepo port.PlacesRepository) *GetSimilarPlacesService {
+	return &GetSimilarPlacesService{placesRepo: placesRepo}
+}
+
+// GetSimilarPlaces returns up to limit places within maxDistance meters that resemble the given place,
+// most similar first. It returns nil if the place doesn't exist.
+func (s *GetSimilarPlacesService) GetSimilarPlaces(ctx context.Context, placeID string, maxDistance float64, limit int) ([]domain.SimilarPlace, error) {
+	target, err := s.placesRepo.GetPlaceFeatures(ctx, placeID)
+	if err != nil || target == nil {
+		return nil, err
+	}
+
+	circle := domain.Circle{Lat: target.Place.Lat, Lng: target.Place.Lng, Radius: maxDistance}
+	candidates, err := s.placesRepo.GetNearbyPlaceFeatures(ctx, circle, maxSimilarCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	similar := []domain.SimilarPlace{}
+	for _, candidate := range candidates {
+		if candidate.Place.ID == placeID {
+			continue
+		}
+		score, reasons := similarity(*target, candidate)
+		if score <= 0 {
+			continue
+		}
+		score *= 1 - distancePenalty*math.Min(candidate.DistanceMeters/maxDistance, 1)
+		similar = append(similar, domain.SimilarPlace{
+			Place:          candidate.Place,
+			Similarity:     math.Round(score*1000) / 1000,
+			DistanceMeters: math.Round(candidate.DistanceMeters),
+			Reasons:        reasons,
+		})
+	}
+
+	sort.SliceStable(similar, func(i, j int) bool {
+		return similar[i].Similarity > similar[j].Similarity
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// similarity scores how much candidate resembles target, ignoring distance
+func similarity(target, candidate domain.PlaceFeatures) (float64, []string) {
+	var score float64
+	var reasons []string
+
+	targetTypes := withoutGenericTypes(target.Types)
+	if shared := intersection(targetTypes, withoutGenericTypes(candidate.Types)); len(shared) > 0 {
+		score += typesWeight * jaccard(targetTypes, withoutGenericTypes(candidate.Types))
+		reasons = append(reasons, "shares types: "+strings.Join(shared, ", "))
+	}
+
+	if target.Place.PrimaryType != "" && target.Place.PrimaryType == candidate.Place.PrimaryType {
+		score += primaryTypeWeight
+		reasons = append(reasons, "same primary type: "+target.Place.PrimaryType)
+	}
+
+	targetPrice, targetHasPrice := priceLevels[target.Place.PriceLevel]
+	candidatePrice, candidateHasPrice := priceLevels[candidate.Place.PriceLevel]
+	if targetHasPrice && candidateHasPrice {
+		switch diff := targetPrice - candidatePrice; {
+		case diff == 0:
+			score += priceLevelWeight
+			reasons = append(reasons, "same price level")
+		case diff == 1 || diff == -1:
+			score += priceLevelWeight / 2
+		}
+	}
+
+	if target.Place.Rating > 0 && ratingBand(target.Place.Rating) == ratingBand(candidate.Place.Rating) {
+		score += ratingBandWeight
+		reasons = append(reasons, fmt.Sprintf("similar rating (%.1f)", candidate.Place.Rating))
+	}
+
+	if shared := intersection(target.Dishes, candidate.Dishes); len(shared) > 0 {
+		score += dishesWeight * jaccard(target.Dishes, candidate.Dishes)
+		reasons = append(reasons, "also serves: "+strings.Join(shared, ", "))
+	}
+
+	if shared := intersection(target.Keywords, candidate.Keywords); len(shared) > 0 {
+		score += keywordsWeight * jaccard(target.Keywords, candidate.Keywords)
+		reasons = append(reasons, "reviewers also mention: "+strings.Join(shared, ", "))
+	}
+
+	return score, reasons
+}
+
+// ratingBand buckets ratings so that 4.3 and 4.4 compare equal but 3.9 and 4.6 don't
+func ratingBand(rating float64) int {
+	switch {
+	case rating >= 4.5:
+		return 3
+	case rating >= 4.0:
+		return 2
+	case rating >= 3.5:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func withoutGenericTypes(types []string) []string {
+	var filtered []string
+	for _, t := range types {
+		if !genericTypes[t] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+func intersection(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, v := range b {
+		inB[v] = true
+	}
+	var shared []string
+	seen := make(map[string]bool)
+	for _, v := range a {
+		if inB[v] && !seen[v] {
+			shared = append(shared, v)
+			seen[v] = true
+		}
+	}
+	return shared
+}
+
+func jaccard(a, b []string) float64 {
+	union := make(map[string]bool, len(a)+len(b))
+	for _, v := range a {
+		union[v] = true
+	}
+	for _, v := range b {
+		union[v] = true
+	}
+	if len(union) == 0 {
+		return 0
+	}
+	return float64(len(intersection(a, b))) / float64(len(union))
+}
@@ -1,46 +1,46 @@
-package storage
-
-import (
-	"context"
-	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-)
-
-type LocalUploader struct {
-}
-
-func NewLocalUploader() *LocalUploader {
-	return &LocalUploader{}
-}
-
-func (u *LocalUploader) Upload(ctx context.Context, src string, dst string) (string, error) {
-	// Ensure the destination directory exists
-	dir := filepath.Dir(dst)
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return "", fmt.Errorf("failed to create destination directory: %w", err)
-	}
-
-	// Open source file
-	srcFile, err := os.Open(src)
-	if err != nil {
-		return "", fmt.Errorf("failed to open source file: %w", err)
-	}
-	defer srcFile.Close()
-
-	// Create destination file
-	dstFile, err := os.Create(dst)
-	if err != nil {
-		return "", fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer dstFile.Close()
-
-	// Copy file content
-	_, err = io.Copy(dstFile, srcFile)
-	if err != nil {
-		return "", fmt.Errorf("failed to copy file: %w", err)
-	}
-
-	return dst, nil
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+type LocalUploader struct {
+}
+
+func NewLocalUploader() *LocalUploader {
+	return &LocalUploader{}
+}
+
+func (u *LocalUploader) Upload(ctx context.Context, src string, dst string) (string, error) {
+	// Ensure the destination directory exists
+	dir := filepath.Dir(dst)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	// Open source file
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer srcFile.Close()
+
+	// Create destination file
+	dstFile, err := os.Create(dst)
+	if err != nil {
+		return "", fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dstFile.Close()
+
+	// Copy file content
+	_, err = io.Copy(dstFile, srcFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return dst, nil
 }
\ No newline at end of file
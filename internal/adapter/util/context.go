@@ -0,0 +1,41 @@
+package util
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// JobContext returns the context a job runs in. It is cancelled on the first SIGINT
+// or SIGTERM, so the job stops starting new work and reports what it finished; a
+// second signal kills the process. JOB_TIMEOUT, e.g. 2h, also cancels it once elapsed.
+func JobContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		signal.Stop(signals) // The next signal gets the default handling
+		log.Printf("Received %v, stopping once the work in flight is done. Send it again to quit at once.", sig)
+		cancel()
+	}()
+
+	value := os.Getenv("JOB_TIMEOUT")
+	if value == "" {
+		return ctx, cancel
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		log.Fatalf("Invalid JOB_TIMEOUT %q, expected a duration such as 90m or 2h", value)
+	}
+	log.Printf("The job stops after %v", timeout)
+	timeoutCtx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	return timeoutCtx, func() {
+		cancelTimeout()
+		cancel()
+	}
+}
\ No newline at end of file
@@ -1,31 +1,31 @@
-package util
-
-import (
-	"github.com/joho/godotenv"
-	"log"
-	"os"
-	"fmt"
-)
-
-func LoadEnv() {
-	if err := godotenv.Load(); err != nil {
-		log.Fatal("Error loading .env file")
-	}
-	os.Setenv("POSTGRES_URI", fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		os.Getenv("POSTGRES_USER"),
-		os.Getenv("POSTGRES_PASSWORD"),
-		os.Getenv("POSTGRES_HOST"),
-		os.Getenv("POSTGRES_PORT"),
-		os.Getenv("POSTGRES_DB"),
-		os.Getenv("POSTGRES_SSL"),
-	))
-
-	// Set MongoDB URI
-	os.Setenv("MONGO_URI", fmt.Sprintf("mongodb://%s:%s@%s:%s/?authSource=%s",
-		os.Getenv("MONGO_USER"),
-		os.Getenv("MONGO_PASSWORD"),
-		os.Getenv("MONGO_HOST"),
-		os.Getenv("MONGO_PORT"),
-		os.Getenv("MONGO_AUTH_SOURCE"),
-	))
+package util
+
+import (
+	"github.com/joho/godotenv"
+	"log"
+	"os"
+	"fmt"
+)
+
+func LoadEnv() {
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("Error loading .env file")
+	}
+	os.Setenv("POSTGRES_URI", fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		os.Getenv("POSTGRES_USER"),
+		os.Getenv("POSTGRES_PASSWORD"),
+		os.Getenv("POSTGRES_HOST"),
+		os.Getenv("POSTGRES_PORT"),
+		os.Getenv("POSTGRES_DB"),
+		os.Getenv("POSTGRES_SSL"),
+	))
+
+	// Set MongoDB URI
+	os.Setenv("MONGO_URI", fmt.Sprintf("mongodb://%s:%s@%s:%s/?authSource=%s",
+		os.Getenv("MONGO_USER"),
+		os.Getenv("MONGO_PASSWORD"),
+		os.Getenv("MONGO_HOST"),
+		os.Getenv("MONGO_PORT"),
+		os.Getenv("MONGO_AUTH_SOURCE"),
+	))
 }
\ No newline at end of file
@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"errors"
+)
+
+// Classes of Places API failures. Adapters wrap them so callers can use errors.Is.
+var (
+	ErrPlacesAPIAuth           = errors.New("places API rejected the credentials")
+	ErrPlacesAPIQuota          = errors.New("places API quota exceeded")
+	ErrPlacesAPIInvalidRequest = errors.New("places API rejected the request")
+	ErrPlacesAPIUnavailable    = errors.New("places API unavailable")
+)
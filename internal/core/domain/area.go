@@ -0,0 +1,164 @@
+package domain
+
+import "time"
+
+// Area levels of the administrative hierarchy, from largest to smallest
+const (
+	AreaLevelCity     = "city"
+	AreaLevelDistrict = "district"
+	AreaLevelWard     = "ward"
+)
+
+// areaLevelRank orders the levels, larger areas first
+var areaLevelRank = map[string]int{
+	AreaLevelCity:     1,
+	AreaLevelDistrict: 2,
+	AreaLevelWard:     3,
+}
+
+// areaLevelTypes maps Places API types to area levels. Vietnamese cities are first-level
+// administrative areas, their districts second-level and their wards third-level or sublocalities.
+var areaLevelTypes = map[string]string{
+	"locality":                    AreaLevelCity,
+	"administrative_area_level_1": AreaLevelCity,
+	"administrative_area_level_2": AreaLevelDistrict,
+	"administrative_area_level_3": AreaLevelWard,
+	"sublocality_level_1":         AreaLevelWard,
+}
+
+// Area is a city, district or ward saved by run-fetch-areas or run-import-areas
+type Area struct {
+	PlaceID   string    `bson:"placeID" json:"place_id"`
+	Name      string    `bson:"name" json:"name"`
+	Level     string    `bson:"level,omitempty" json:"level,omitempty"`       // city, district or ward, empty for areas saved before levels
+	ParentID  string    `bson:"parentID,omitempty" json:"parent_id,omitempty"` // PlaceID of the area one level up that contains this one
+	MinLat    float64   `bson:"minLat" json:"min_lat"`
+	MaxLat    float64   `bson:"maxLat" json:"max_lat"`
+	MinLng    float64   `bson:"minLng" json:"min_lng"`
+	MaxLng    float64   `bson:"maxLng" json:"max_lng"`
+	Query     string    `bson:"query" json:"query"`
+	Boundary  Boundary  `bson:"boundary,omitempty" json:"boundary,omitempty"` // Real outline, set with run-set-area-boundary or run-import-areas
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+}
+
+// Outline is the area's boundary, or its viewport rectangle when no boundary was set
+func (a Area) Outline() Boundary {
+	if len(a.Boundary) > 0 {
+		return a.Boundary
+	}
+	return Boundary{{Ring{
+		{Lat: a.MinLat, Lng: a.MinLng},
+		{Lat: a.MinLat, Lng: a.MaxLng},
+		{Lat: a.MaxLat, Lng: a.MaxLng},
+		{Lat: a.MaxLat, Lng: a.MinLng},
+	}}}
+}
+
+// AreaLevelOfTypes returns the level of the first of the types that is an administrative area, or empty
+func AreaLevelOfTypes(types []string) string {
+	for _, t := range types {
+		if level, ok := areaLevelTypes[t]; ok {
+			return level
+		}
+	}
+	return ""
+}
+
+// LinkAreaParents sets the ParentID of every area with a level to the area of the closest
+// larger level that holds most of its outline, and returns the areas whose parent changed
+func LinkAreaParents(areas []Area) []Area {
+	var changed []Area
+	for i, child := range areas {
+		rank, ok := areaLevelRank[child.Level]
+		if !ok {
+			continue
+		}
+		probes := probePoints(child.Outline())
+
+		parentID, parentRank := "", 0
+		for _, candidate := range areas {
+			candidateRank, ok := areaLevelRank[candidate.Level]
+			if !ok || candidateRank >= rank || candidateRank <= parentRank {
+				continue
+			}
+			// Most of the outline, since neighbours share the points on their common border
+			contained := 0
+			outline := candidate.Outline()
+			for _, p := range probes {
+				if outline.Contains(p) {
+					contained++
+				}
+			}
+			if contained*2 > len(probes) {
+				parentID, parentRank = candidate.PlaceID, candidateRank
+			}
+		}
+
+		if parentID != child.ParentID {
+			areas[i].ParentID = parentID
+			changed = append(changed, areas[i])
+		}
+	}
+	return changed
+}
+
+// probePoints returns the center of the boundary's bounding box and up to 32 points of each outer ring
+func probePoints(boundary Boundary) []LatLng {
+	minLat, maxLat, minLng, maxLng := boundary.BoundingBox()
+	points := []LatLng{{Lat: (minLat + maxLat) / 2, Lng: (minLng + maxLng) / 2}}
+	for _, polygon := range boundary {
+		if len(polygon) == 0 {
+			continue
+		}
+		outer := polygon[0]
+		step := len(outer)/32 + 1
+		for i := 0; i < len(outer); i += step {
+			points = append(points, outer[i])
+		}
+	}
+	return points
+}
+
+// AreaLocator finds the city, district and ward a point lies in. Only areas with a
+// real boundary are used, viewports overlap too much to tell neighbours apart.
+type AreaLocator struct {
+	areas map[string][]Area // By level
+}
+
+func NewAreaLocator(areas []Area) *AreaLocator {
+	l := &AreaLocator{areas: make(map[string][]Area)}
+	for _, area := range areas {
+		if _, ok := areaLevelRank[area.Level]; ok && len(area.Boundary) > 0 {
+			// The viewport may not hold the whole boundary, bound the boundary itself
+			area.MinLat, area.MaxLat, area.MinLng, area.MaxLng = area.Boundary.BoundingBox()
+			l.areas[area.Level] = append(l.areas[area.Level], area)
+		}
+	}
+	return l
+}
+
+// Len is the number of areas the locator places points in
+func (l *AreaLocator) Len() int {
+	n := 0
+	for _, areas := range l.areas {
+		n += len(areas)
+	}
+	return n
+}
+
+// Locate returns the names of the city, district and ward containing p, empty where none does
+func (l *AreaLocator) Locate(p LatLng) (city, district, ward string) {
+	return l.locate(AreaLevelCity, p), l.locate(AreaLevelDistrict, p), l.locate(AreaLevelWard, p)
+}
+
+func (l *AreaLocator) locate(level string, p LatLng) string {
+	for _, area := range l.areas[level] {
+		if p.Lat < area.MinLat || p.Lat > area.MaxLat || p.Lng < area.MinLng || p.Lng > area.MaxLng {
+			continue
+		}
+		if area.Boundary.Contains(p) {
+			return area.Name
+		}
+	}
+	return ""
+}
\ No newline at end of file
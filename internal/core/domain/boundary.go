@@ -0,0 +1,114 @@
+package domain
+
+import "math"
+
+// LatLng is a point in degrees
+type LatLng struct {
+	Lat float64 `bson:"lat" json:"lat"`
+	Lng float64 `bson:"lng" json:"lng"`
+}
+
+// Ring is a closed line of points, the last point joining back to the first
+type Ring []LatLng
+
+// Polygon is an outer ring followed by its holes
+type Polygon []Ring
+
+// Boundary is the shape a crawl is limited to, e.g. a district made of one or more polygons
+type Boundary []Polygon
+
+// Contains reports whether p lies inside the boundary. An empty boundary contains every point.
+func (b Boundary) Contains(p LatLng) bool {
+	if len(b) == 0 {
+		return true
+	}
+	for _, polygon := range b {
+		if polygon.Contains(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p Polygon) Contains(pt LatLng) bool {
+	if len(p) == 0 || !p[0].contains(pt) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.contains(pt) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains uses the even-odd rule, treating lat/lng as planar
+func (r Ring) contains(pt LatLng) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if (a.Lat > pt.Lat) != (b.Lat > pt.Lat) &&
+			pt.Lng < (b.Lng-a.Lng)*(pt.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// IntersectsCircle reports whether any part of the circle lies inside the boundary
+func (b Boundary) IntersectsCircle(c Circle) bool {
+	if b.Contains(LatLng{Lat: c.Lat, Lng: c.Lng}) {
+		return true
+	}
+	// Otherwise the circle can only reach inside by crossing an edge
+	for _, polygon := range b {
+		for _, ring := range polygon {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				if distanceToSegment(c, ring[j], ring[i]) <= c.Radius {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// BoundingBox returns the smallest lat/lng box around the boundary
+func (b Boundary) BoundingBox() (minLat, maxLat, minLng, maxLng float64) {
+	minLat, minLng = math.Inf(1), math.Inf(1)
+	maxLat, maxLng = math.Inf(-1), math.Inf(-1)
+	for _, polygon := range b {
+		for _, ring := range polygon {
+			for _, p := range ring {
+				minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+				minLng, maxLng = math.Min(minLng, p.Lng), math.Max(maxLng, p.Lng)
+			}
+		}
+	}
+	return minLat, maxLat, minLng, maxLng
+}
+
+// offsetMeters moves x meters east and y meters north of the circle's center
+func offsetMeters(c Circle, x, y float64) LatLng {
+	return LatLng{
+		Lat: c.Lat + y*LatMeterToDegree,
+		Lng: c.Lng + x*LatMeterToDegree/math.Cos(c.Lat*math.Pi/180),
+	}
+}
+
+// toMeters projects p onto a plane centered on the circle, in meters east and north
+func toMeters(c Circle, p LatLng) (x, y float64) {
+	return (p.Lng - c.Lng) / LatMeterToDegree * math.Cos(c.Lat*math.Pi/180), (p.Lat - c.Lat) / LatMeterToDegree
+}
+
+// distanceToSegment is the distance in meters from the circle's center to the segment ab
+func distanceToSegment(c Circle, a, b LatLng) float64 {
+	ax, ay := toMeters(c, a)
+	bx, by := toMeters(c, b)
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
\ No newline at end of file
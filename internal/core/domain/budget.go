@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+)
+
+// ErrBudgetExhausted is returned instead of calling the Places API once a request or cost cap is reached
+var ErrBudgetExhausted = errors.New("places API budget exhausted")
+
+// SKU is a billable Places API (New) SKU. Which one a request bills depends
+// on the endpoint and on the most expensive field in its field mask.
+type SKU string
+
+const (
+	SKUNearbySearchPro                  SKU = "nearby_search_pro"
+	SKUNearbySearchEnterprise           SKU = "nearby_search_enterprise"
+	SKUNearbySearchEnterpriseAtmosphere SKU = "nearby_search_enterprise_atmosphere"
+	SKUTextSearchEssentials             SKU = "text_search_essentials"
+	SKUTextSearchPro                    SKU = "text_search_pro"
+	SKUTextSearchEnterprise             SKU = "text_search_enterprise"
+	SKUTextSearchEnterpriseAtmosphere   SKU = "text_search_enterprise_atmosphere"
+	SKUPlaceDetailsPro                  SKU = "place_details_pro"
+	SKUPlaceDetailsEnterprise           SKU = "place_details_enterprise"
+	SKUPlaceDetailsEnterpriseAtmosphere SKU = "place_details_enterprise_atmosphere"
+)
+
+// SKUCostPerRequest is the list price in USD of one request, before free monthly usage
+var SKUCostPerRequest = map[SKU]float64{
+	SKUNearbySearchPro:                  32.0 / 1000,
+	SKUNearbySearchEnterprise:           35.0 / 1000,
+	SKUNearbySearchEnterpriseAtmosphere: 40.0 / 1000,
+	SKUTextSearchEssentials:             0,
+	SKUTextSearchPro:                    32.0 / 1000,
+	SKUTextSearchEnterprise:             35.0 / 1000,
+	SKUTextSearchEnterpriseAtmosphere:   40.0 / 1000,
+	SKUPlaceDetailsPro:                  17.0 / 1000,
+	SKUPlaceDetailsEnterprise:           20.0 / 1000,
+	SKUPlaceDetailsEnterpriseAtmosphere: 25.0 / 1000,
+}
+
+// BudgetLimits caps Places API usage. A zero value means no cap.
+type BudgetLimits struct {
+	MaxRequestsPerRun int
+	MaxRequestsPerDay int
+	MaxCostPerRun     float64 // USD
+	MaxCostPerDay     float64 // USD
+}
+
+// RateLimits paces Places API requests. A zero value means no limit.
+type RateLimits struct {
+	QPS           float64 // Sustained requests per second
+	Burst         int     // Requests that may go out at once after an idle spell
+	MaxConcurrent int     // Requests in flight at the same time
+}
+
+// APIUsage is the Places API usage of a run or of a day
+type APIUsage struct {
+	Date     string         `bson:"date"` // YYYY-MM-DD (UTC), empty for a run
+	Requests int            `bson:"requests"`
+	Cost     float64        `bson:"cost"` // Estimated USD
+	SKUs     map[string]int `bson:"skus"` // Requests per SKU
+}
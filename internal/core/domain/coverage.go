@@ -0,0 +1,297 @@
+package domain
+
+import "math"
+
+// coverageResolution is the width in meters of the smallest cell CoveredFraction
+// looks at. A gap between covering circles narrower than this may go unnoticed.
+const coverageResolution = 10.0
+
+// areaCoverageSteps is the number of sample rows and columns across an area's
+// bounding box when measuring how much of it has been crawled
+const areaCoverageSteps = 100
+
+// clippedAreaSteps is the number of sample rows across a covered cell that the
+// boundary or the circle's rim cuts through, to estimate how much of it counts
+const clippedAreaSteps = 8
+
+// CoveredFraction returns the share of the part of the circle inside the boundary that
+// lies in at least one of the covering circles. Overlapping covering circles are counted
+// once. A circle with no part inside the boundary is fully covered.
+//
+// The circle's square is split into quarters until each cell is inside one covering
+// circle, outside all of them, or coverageResolution wide, so narrow gaps are found
+// whatever the size of the circle.
+func CoveredFraction(c Circle, boundary Boundary, covering []Circle) float64 {
+	q := coverageQuad{center: c, boundary: boundary}
+	var disks []disk
+	for _, other := range covering {
+		x, y := toMeters(c, LatLng{Lat: other.Lat, Lng: other.Lng})
+		if math.Hypot(x, y) < c.Radius+other.Radius {
+			disks = append(disks, disk{x: x, y: y, r: other.Radius})
+		}
+	}
+	var edges []segment
+	for _, polygon := range boundary {
+		for _, ring := range polygon {
+			for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+				ax, ay := toMeters(c, ring[j])
+				bx, by := toMeters(c, ring[i])
+				edges = append(edges, segment{ax: ax, ay: ay, bx: bx, by: by})
+			}
+		}
+	}
+
+	total, covered := q.visit(cell{x: -c.Radius, y: -c.Radius, size: 2 * c.Radius}, disks, edges)
+	if total == 0 {
+		return 1
+	}
+	return covered / total
+}
+
+// coverageQuad measures coverage in meters east and north of the circle's center
+type coverageQuad struct {
+	center   Circle
+	boundary Boundary
+}
+
+// cell is a square with its south-west corner at x, y
+type cell struct {
+	x, y, size float64
+}
+
+type disk struct {
+	x, y, r float64
+}
+
+// segment is a boundary edge from a to b
+type segment struct {
+	ax, ay, bx, by float64
+}
+
+// visit returns the area of the cell inside the circle and the boundary, and how much
+// of it the disks cover. Only the disks and edges reaching the cell are passed down.
+func (q coverageQuad) visit(cl cell, disks []disk, edges []segment) (total, covered float64) {
+	r := q.center.Radius
+	if cl.nearest(0, 0) > r {
+		return 0, 0
+	}
+	inCircle := cl.farthest(0, 0) <= r
+
+	var crossing []segment
+	for _, e := range edges {
+		if e.crosses(cl) {
+			crossing = append(crossing, e)
+		}
+	}
+	inBoundary := len(crossing) == 0
+	if inBoundary && !q.boundary.Contains(q.point(cl.x+cl.size/2, cl.y+cl.size/2)) {
+		return 0, 0
+	}
+
+	var reaching []disk
+	for _, d := range disks {
+		if cl.farthest(d.x, d.y) <= d.r {
+			area := cl.size * cl.size
+			if !inCircle || !inBoundary {
+				area = q.clippedArea(cl)
+			}
+			return area, area
+		}
+		if cl.nearest(d.x, d.y) <= d.r {
+			reaching = append(reaching, d)
+		}
+	}
+	if len(reaching) == 0 && inCircle && inBoundary {
+		return cl.size * cl.size, 0
+	}
+
+	if cl.size <= coverageResolution {
+		x, y := cl.x+cl.size/2, cl.y+cl.size/2
+		if math.Hypot(x, y) > r || !q.boundary.Contains(q.point(x, y)) {
+			return 0, 0
+		}
+		for _, d := range reaching {
+			if math.Hypot(x-d.x, y-d.y) <= d.r {
+				return cl.size * cl.size, cl.size * cl.size
+			}
+		}
+		return cl.size * cl.size, 0
+	}
+
+	half := cl.size / 2
+	for _, quarter := range []cell{
+		{x: cl.x, y: cl.y, size: half},
+		{x: cl.x + half, y: cl.y, size: half},
+		{x: cl.x, y: cl.y + half, size: half},
+		{x: cl.x + half, y: cl.y + half, size: half},
+	} {
+		t, c := q.visit(quarter, reaching, crossing)
+		total += t
+		covered += c
+	}
+	return total, covered
+}
+
+// clippedArea estimates the area of the cell inside both the circle and the boundary
+func (q coverageQuad) clippedArea(cl cell) float64 {
+	step := cl.size / clippedAreaSteps
+	inside := 0
+	for row := 0; row < clippedAreaSteps; row++ {
+		for col := 0; col < clippedAreaSteps; col++ {
+			x, y := cl.x+(float64(col)+0.5)*step, cl.y+(float64(row)+0.5)*step
+			if math.Hypot(x, y) <= q.center.Radius && q.boundary.Contains(q.point(x, y)) {
+				inside++
+			}
+		}
+	}
+	return float64(inside) * step * step
+}
+
+func (q coverageQuad) point(x, y float64) LatLng {
+	return offsetMeters(q.center, x, y)
+}
+
+// nearest is the distance from x, y to the closest point of the cell
+func (cl cell) nearest(x, y float64) float64 {
+	dx := math.Max(0, math.Max(cl.x-x, x-(cl.x+cl.size)))
+	dy := math.Max(0, math.Max(cl.y-y, y-(cl.y+cl.size)))
+	return math.Hypot(dx, dy)
+}
+
+// farthest is the distance from x, y to the farthest corner of the cell
+func (cl cell) farthest(x, y float64) float64 {
+	dx := math.Max(math.Abs(cl.x-x), math.Abs(cl.x+cl.size-x))
+	dy := math.Max(math.Abs(cl.y-y), math.Abs(cl.y+cl.size-y))
+	return math.Hypot(dx, dy)
+}
+
+// crosses reports whether the segment passes through the cell, clipping it to the
+// cell's sides (Liang-Barsky)
+func (s segment) crosses(cl cell) bool {
+	dx, dy := s.bx-s.ax, s.by-s.ay
+	t0, t1 := 0.0, 1.0
+	for _, side := range [4][2]float64{
+		{-dx, s.ax - cl.x},
+		{dx, cl.x + cl.size - s.ax},
+		{-dy, s.ay - cl.y},
+		{dy, cl.y + cl.size - s.ay},
+	} {
+		p, dist := side[0], side[1]
+		if p == 0 {
+			if dist < 0 {
+				return false
+			}
+			continue
+		}
+		t := dist / p
+		if p < 0 {
+			if t > t1 {
+				return false
+			}
+			t0 = math.Max(t0, t)
+		} else {
+			if t < t0 {
+				return false
+			}
+			t1 = math.Min(t1, t)
+		}
+	}
+	return true
+}
+
+// ScannedFraction is CoveredFraction over the stored circles crawled with at least
+// fieldLevel. Circles that hit MaxResultsPerReq may hide places, so they cover nothing
+// themselves, only the smaller circles they were subdivided into do.
+func ScannedFraction(c Circle, boundary Boundary, stored []SearchCircle, fieldLevel int) float64 {
+	var covering []Circle
+	for _, s := range stored {
+		if !s.Saturated() && FieldLevelOf(s.FieldMask) >= fieldLevel {
+			covering = append(covering, s.Circle)
+		}
+	}
+	return CoveredFraction(c, boundary, covering)
+}
+
+// DistanceMeters is the great-circle distance between two points
+func DistanceMeters(a, b LatLng) float64 {
+	dLat := (b.Lat - a.Lat) * (math.Pi / 180)
+	dLng := (b.Lng - a.Lng) * (math.Pi / 180)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a.Lat*(math.Pi/180))*math.Cos(b.Lat*(math.Pi/180))*
+			math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return EarthRadius * 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+}
+
+// CoverageReport is what the stored search circles of a category cover
+type CoverageReport struct {
+	Category string         `json:"category"`
+	Circles  []SearchCircle `json:"-"`
+	Areas    []AreaCoverage `json:"areas"`
+}
+
+// AreaCoverage splits a stored area into the share crawled completely, the share only
+// crawled by circles that hit the result cap, and the share never crawled
+type AreaCoverage struct {
+	Area             Area    `json:"-"`
+	Name             string  `json:"name"`
+	Circles          int     `json:"circles"`           // Search circles reaching into the area
+	CoveredPercent   float64 `json:"covered_percent"`   // In a circle that returned fewer than MaxResultsPerReq places
+	SaturatedPercent float64 `json:"saturated_percent"` // Only in circles that hit the cap, so places may be missing
+	UncoveredPercent float64 `json:"uncovered_percent"` // In no circle at all
+}
+
+// MeasureAreaCoverage samples a fine grid over the area's outline and sorts each point
+// by the circles it falls in
+func MeasureAreaCoverage(area Area, circles []SearchCircle) AreaCoverage {
+	outline := area.Outline()
+	coverage := AreaCoverage{Area: area, Name: area.Name}
+
+	var reaching []SearchCircle
+	for _, c := range circles {
+		if outline.IntersectsCircle(c.Circle) {
+			reaching = append(reaching, c)
+		}
+	}
+	coverage.Circles = len(reaching)
+
+	minLat, maxLat, minLng, maxLng := outline.BoundingBox()
+	latStep := (maxLat - minLat) / areaCoverageSteps
+	lngStep := (maxLng - minLng) / areaCoverageSteps
+	total, covered, saturated := 0, 0, 0
+	for row := 0; row < areaCoverageSteps; row++ {
+		for col := 0; col < areaCoverageSteps; col++ {
+			p := LatLng{Lat: minLat + (float64(row)+0.5)*latStep, Lng: minLng + (float64(col)+0.5)*lngStep}
+			if !outline.Contains(p) {
+				continue
+			}
+			total++
+			reached, complete := false, false
+			for _, c := range reaching {
+				if DistanceMeters(p, LatLng{Lat: c.Circle.Lat, Lng: c.Circle.Lng}) <= c.Circle.Radius {
+					reached = true
+					if !c.Saturated() {
+						complete = true
+						break
+					}
+				}
+			}
+			switch {
+			case complete:
+				covered++
+			case reached:
+				saturated++
+			}
+		}
+	}
+
+	if total > 0 {
+		coverage.CoveredPercent = float64(covered) / float64(total) * 100
+		coverage.SaturatedPercent = float64(saturated) / float64(total) * 100
+		coverage.UncoveredPercent = 100 - coverage.CoveredPercent - coverage.SaturatedPercent
+	} else {
+		coverage.UncoveredPercent = 100
+	}
+	return coverage
+}
\ No newline at end of file
@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// circleAt is a circle of radius meters, x meters east and y meters north of c's center
+func circleAt(c Circle, x, y, radius float64) Circle {
+	p := offsetMeters(c, x, y)
+	return Circle{Lat: p.Lat, Lng: p.Lng, Radius: radius}
+}
+
+// boxBoundary is the rectangle from (west, south) to (east, north), in meters from c's center
+func boxBoundary(c Circle, west, south, east, north float64) Boundary {
+	sw, ne := offsetMeters(c, west, south), offsetMeters(c, east, north)
+	return Boundary{{{
+		{Lat: sw.Lat, Lng: sw.Lng},
+		{Lat: sw.Lat, Lng: ne.Lng},
+		{Lat: ne.Lat, Lng: ne.Lng},
+		{Lat: ne.Lat, Lng: sw.Lng},
+	}}}
+}
+
+func TestCoveredFraction(t *testing.T) {
+	for _, lat := range []float64{0, 10.7769, 48.8566, -33.8688} {
+		c := Circle{Lat: lat, Lng: 106.7009, Radius: 1000}
+		westHalf := boxBoundary(c, -2000, -2000, 0, 2000)
+
+		// Two circles 500m either side of the center reach every point of the rim
+		// within 1118m, so with 1150m they cover it together while overlapping
+		eastCircle := circleAt(c, 500, 0, 1150)
+		westCircle := circleAt(c, -500, 0, 1150)
+
+		// A circle of the same radius whose center is on the rim covers the lens
+		// between them, (2π/3 - √3/2) / π of the circle
+		lens := (2*math.Pi/3 - math.Sqrt(3)/2) / math.Pi
+
+		tests := []struct {
+			name      string
+			boundary  Boundary
+			covering  []Circle
+			want      float64
+			tolerance float64
+		}{
+			{"nothing stored", nil, nil, 0, 0},
+			{"same circle", nil, []Circle{c}, 1, 0},
+			{"larger concentric circle", nil, []Circle{circleAt(c, 0, 0, 1500)}, 1, 0},
+			{"smaller concentric circle", nil, []Circle{circleAt(c, 0, 0, 500)}, 0.25, 0.03},
+			{"far away circle", nil, []Circle{circleAt(c, 5000, 0, 1000)}, 0, 0},
+			{"lens", nil, []Circle{circleAt(c, 1000, 0, 1000)}, lens, 0.03},
+			{"overlapping circles cover it together", nil, []Circle{eastCircle, westCircle}, 1, 0},
+			{"overlap is counted once", nil, []Circle{circleAt(c, 1000, 0, 1000), circleAt(c, 1000, 0, 1000)}, lens, 0.03},
+			{"boundary clips the uncovered half", westHalf, []Circle{westCircle}, 1, 0},
+			{"boundary clips the covered half", westHalf, []Circle{circleAt(c, 1000, 0, 1000)}, 0, 0},
+			{"boundary with nothing stored", westHalf, nil, 0, 0},
+			{"boundary misses the circle", boxBoundary(c, 5000, 5000, 6000, 6000), nil, 1, 0},
+		}
+
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s at latitude %.2f", tt.name, lat), func(t *testing.T) {
+				got := CoveredFraction(c, tt.boundary, tt.covering)
+				if math.Abs(got-tt.want) > tt.tolerance {
+					t.Errorf("got %.4f, want %.4f ± %.2f", got, tt.want, tt.tolerance)
+				}
+			})
+		}
+	}
+}
+
+func TestCoveredFractionFindsNarrowGaps(t *testing.T) {
+	for _, radius := range []float64{1000, 5000, MaxSearchRadius} {
+		// A negative gap makes the covering circles overlap by a twentieth of the radius,
+		// more than their rims curve away from each other within the circle
+		for _, gap := range []float64{300, 50, 20, -radius / 20} {
+			t.Run(fmt.Sprintf("%.0fm gap through a %.0fm circle", gap, radius), func(t *testing.T) {
+				c := Circle{Lat: 10.7769, Lng: 106.7009, Radius: radius}
+
+				// Two much larger circles whose nearly straight rims run north-south,
+				// gap meters apart through the center
+				far := 50 * radius
+				covering := []Circle{circleAt(c, -far-gap/2, 0, far), circleAt(c, far+gap/2, 0, far)}
+
+				got := CoveredFraction(c, nil, covering)
+				if gap > 0 && got >= 1 {
+					t.Errorf("got %.6f, want the %.0fm strip left uncovered", got, gap)
+				}
+				if gap < 0 && got != 1 {
+					t.Errorf("got %.6f, want 1", got)
+				}
+			})
+		}
+	}
+}
+
+func TestScannedFraction(t *testing.T) {
+	c := Circle{Lat: 10.7769, Lng: 106.7009, Radius: 1000}
+	placeIDs := func(n int) []string {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = string(rune('a' + i))
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name       string
+		stored     []SearchCircle
+		fieldLevel int
+		want       float64
+	}{
+		{"complete circle", []SearchCircle{{Circle: c, PlaceIDs: placeIDs(MaxResultsPerReq - 1)}}, FieldLevelBasic, 1},
+		{"empty circle", []SearchCircle{{Circle: c}}, FieldLevelBasic, 1},
+		{"saturated circle", []SearchCircle{{Circle: c, PlaceIDs: placeIDs(MaxResultsPerReq)}}, FieldLevelBasic, 0},
+		{
+			"saturated circle next to a complete one",
+			[]SearchCircle{
+				{Circle: circleAt(c, 0, 0, 5000), PlaceIDs: placeIDs(MaxResultsPerReq)},
+				{Circle: c, PlaceIDs: placeIDs(3)},
+			},
+			FieldLevelBasic, 1,
+		},
+		{"crawled with fewer fields", []SearchCircle{{Circle: c, FieldMask: FieldMaskBasic.Name}}, FieldLevelContact, 0},
+		{"crawled with more fields", []SearchCircle{{Circle: c, FieldMask: FieldMaskFull.Name}}, FieldLevelContact, 1},
+		{"crawled before field masks", []SearchCircle{{Circle: c}}, FieldLevelFull, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScannedFraction(c, nil, tt.stored, tt.fieldLevel); got != tt.want {
+				t.Errorf("got %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
\ No newline at end of file
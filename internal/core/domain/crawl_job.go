@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Crawl job statuses
+const (
+	CrawlJobRunning    = "running"
+	CrawlJobCompleted  = "completed"
+	CrawlJobIncomplete = "incomplete" // Stopped with pending or failed circles left, can be resumed
+)
+
+// Crawl circle statuses
+const (
+	CrawlCirclePending = "pending"
+	CrawlCircleDone    = "done"
+	CrawlCircleFailed  = "failed"
+)
+
+// CrawlJob is a persisted place crawl over a bounding box, resumable by ID
+type CrawlJob struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Category  string             `bson:"category"`
+	MinLat    float64            `bson:"minLat"`
+	MaxLat    float64            `bson:"maxLat"`
+	MinLng    float64            `bson:"minLng"`
+	MaxLng    float64            `bson:"maxLng"`
+	Boundary  Boundary           `bson:"boundary,omitempty"`  // Limits the crawl to a polygon within the box
+	Grid      GridOptions        `bson:"grid"`                // Zero for jobs created before the grid was configurable
+	FieldMask string             `bson:"fieldMask,omitempty"` // Profile name, empty for jobs created before field masks
+	Status    string             `bson:"status"`
+	CreatedAt time.Time          `bson:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+// FieldMaskProfile is the field mask the job crawls with. Jobs created before
+// field masks existed were crawled with every field.
+func (j CrawlJob) FieldMaskProfile() (FieldMask, error) {
+	if j.FieldMask == "" {
+		return FieldMaskFull, nil
+	}
+	return FieldMaskByName(j.FieldMask)
+}
+
+// CrawlCircle is one circle of a crawl job's frontier
+type CrawlCircle struct {
+	JobID     primitive.ObjectID `bson:"jobId"`
+	Lat       float64            `bson:"lat"`
+	Lng       float64            `bson:"lng"`
+	Radius    float64            `bson:"radius"`
+	Status    string             `bson:"status"`
+	NumPlaces int64              `bson:"numPlaces"`
+	Error     string             `bson:"error,omitempty"`
+	UpdatedAt time.Time          `bson:"updatedAt"`
+}
+
+func (c CrawlCircle) Circle() Circle {
+	return Circle{Lat: c.Lat, Lng: c.Lng, Radius: c.Radius}
+}
+
+// CrawlReport summarizes a place crawl
+type CrawlReport struct {
+	JobID           string
+	Category        string
+	TotalCells      int // Circles the run started from: the initial grid, or the frontier when resuming
+	CompletedCells  int // Of those, circles fully crawled including their subdivisions
+	PendingCircles  int // Circles of the job left pending or failed, to be resumed
+	Requests        int // Places API requests made
+	BudgetExhausted bool
+	Interrupted     bool // Stopped by a signal or the job timeout
+}
+
+// CoveragePercent is the share of the starting circles that was fully crawled
+func (r CrawlReport) CoveragePercent() float64 {
+	if r.TotalCells == 0 {
+		return 100
+	}
+	return float64(r.CompletedCells) / float64(r.TotalCells) * 100
+}
+
+// PlannedCircle is a circle of the initial grid of a planned crawl
+type PlannedCircle struct {
+	Circle
+	Scanned bool // Already covered by earlier search results, so not requested again
+}
+
+// CrawlPlan is what a crawl would do, worked out without calling the Places API
+type CrawlPlan struct {
+	Category          string
+	Circles           []PlannedCircle
+	ScannedCircles    int
+	BestCaseRequests  int     // No circle is dense enough to be subdivided
+	WorstCaseRequests float64 // Every circle is subdivided down to the minimum radius, which can exceed an int
+}
+
+// EstimatedCost is the list price in USD of the best and worst case
+func (p CrawlPlan) EstimatedCost(sku SKU) (best, worst float64) {
+	return float64(p.BestCaseRequests) * SKUCostPerRequest[sku], p.WorstCaseRequests * SKUCostPerRequest[sku]
+}
\ No newline at end of file
@@ -0,0 +1,9 @@
+package domain
+
+// PlaceDish records how often a dish is mentioned for a place
+type PlaceDish struct {
+	PlaceID      string `db:"place_id"`
+	Dish         string `db:"dish"`          // Canonical dish name from the dish dictionary
+	MentionCount int    `db:"mention_count"` // Mentions across the place's reviews
+	InName       bool   `db:"in_name"`       // The dish appears in the place name
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Field mask levels. Profiles are nested: each one asks for every field of the levels below it.
+const (
+	FieldLevelBasic = iota
+	FieldLevelContact
+	FieldLevelAtmosphere
+	FieldLevelFull
+)
+
+// FieldMask is a named set of Places API fields requested per crawl purpose.
+// The most expensive field in the mask decides which SKU a request bills.
+type FieldMask struct {
+	Name            string
+	Level           int
+	Fields          []string // Place fields, nil asks for every field
+	NearbySearchSKU SKU
+	TextSearchSKU   SKU
+	PlaceDetailsSKU SKU
+}
+
+var basicFields = []string{
+	"id", "displayName", "types", "primaryType", "location", "viewport", "formattedAddress",
+	"shortFormattedAddress", "googleMapsUri", "iconMaskBaseUri", "iconBackgroundColor",
+	"utcOffsetMinutes", "businessStatus", "photos",
+}
+
+var contactFields = []string{
+	"nationalPhoneNumber", "internationalPhoneNumber", "websiteUri", "rating", "userRatingCount",
+	"priceLevel", "regularOpeningHours", "currentOpeningHours",
+}
+
+var atmosphereFields = []string{
+	"reviews", "editorialSummary", "takeout", "dineIn", "delivery", "goodForGroups", "liveMusic",
+	"restroom", "servesBreakfast", "servesLunch", "servesDinner", "servesBeer", "servesWine",
+	"servesVegetarianFood", "outdoorSeating", "reservable",
+}
+
+var (
+	FieldMaskBasic = FieldMask{
+		Name:            "basic",
+		Level:           FieldLevelBasic,
+		Fields:          basicFields,
+		NearbySearchSKU: SKUNearbySearchPro,
+		TextSearchSKU:   SKUTextSearchPro,
+		PlaceDetailsSKU: SKUPlaceDetailsPro,
+	}
+	FieldMaskContact = FieldMask{
+		Name:            "contact",
+		Level:           FieldLevelContact,
+		Fields:          concat(basicFields, contactFields),
+		NearbySearchSKU: SKUNearbySearchEnterprise,
+		TextSearchSKU:   SKUTextSearchEnterprise,
+		PlaceDetailsSKU: SKUPlaceDetailsEnterprise,
+	}
+	FieldMaskAtmosphere = FieldMask{
+		Name:            "atmosphere",
+		Level:           FieldLevelAtmosphere,
+		Fields:          concat(basicFields, contactFields, atmosphereFields),
+		NearbySearchSKU: SKUNearbySearchEnterpriseAtmosphere,
+		TextSearchSKU:   SKUTextSearchEnterpriseAtmosphere,
+		PlaceDetailsSKU: SKUPlaceDetailsEnterpriseAtmosphere,
+	}
+	FieldMaskFull = FieldMask{
+		Name:            "full",
+		Level:           FieldLevelFull,
+		NearbySearchSKU: SKUNearbySearchEnterpriseAtmosphere,
+		TextSearchSKU:   SKUTextSearchEnterpriseAtmosphere,
+		PlaceDetailsSKU: SKUPlaceDetailsEnterpriseAtmosphere,
+	}
+)
+
+var fieldMasks = []FieldMask{FieldMaskBasic, FieldMaskContact, FieldMaskAtmosphere, FieldMaskFull}
+
+// FieldMaskByName returns the profile with the given name
+func FieldMaskByName(name string) (FieldMask, error) {
+	var names []string
+	for _, mask := range fieldMasks {
+		if mask.Name == name {
+			return mask, nil
+		}
+		names = append(names, mask.Name)
+	}
+	return FieldMask{}, fmt.Errorf("unknown field mask %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// FieldLevelOf returns the level of the named profile. Search results saved before
+// profiles existed have no name and were crawled with every field.
+func FieldLevelOf(name string) int {
+	if mask, err := FieldMaskByName(name); err == nil {
+		return mask.Level
+	}
+	return FieldLevelFull
+}
+
+// Header formats the mask for the X-Goog-FieldMask header, each field prefixed with prefix
+func (m FieldMask) Header(prefix string) string {
+	if m.Fields == nil {
+		return "*"
+	}
+	fields := make([]string, len(m.Fields))
+	for i, field := range m.Fields {
+		fields[i] = prefix + field
+	}
+	return strings.Join(fields, ",")
+}
+
+func concat(lists ...[]string) []string {
+	var all []string
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	return all
+}
\ No newline at end of file
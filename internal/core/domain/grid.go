@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	MaxSearchRadius     = 50000.0 // Largest radius the Places API accepts, in meters
+	DefaultGridOverlap  = 0.1     // Default margin of a crawl circle beyond its cell, see GridOptions
+	MaxSubdivisionDepth = 20      // Most circle sizes a dense crawl may go through, 7^20 circles at the smallest
+)
+
+// GridOptions shapes the circles a crawl covers an area with
+type GridOptions struct {
+	// Overlap is the margin added to each circle's radius, as a share of the radius that
+	// would just cover its cell, so neighbouring circles overlap by a little.
+	Overlap float64 `bson:"overlap"`
+	// MinRadius stops the subdivision of dense circles, in meters
+	MinRadius float64 `bson:"minRadius"`
+}
+
+func DefaultGridOptions() GridOptions {
+	return GridOptions{Overlap: DefaultGridOverlap, MinRadius: MinRadius}
+}
+
+func (o GridOptions) Validate() error {
+	if o.Overlap < 0 || o.Overlap >= 1 {
+		return fmt.Errorf("grid overlap must be at least 0 and less than 1, got %g", o.Overlap)
+	}
+	if o.MinRadius <= 0 || o.MinRadius > MaxSearchRadius {
+		return fmt.Errorf("minimum radius must be more than 0 and at most %gm, got %gm", MaxSearchRadius, o.MinRadius)
+	}
+	if depth := o.SubdivisionDepth(MaxSearchRadius); depth > MaxSubdivisionDepth {
+		return fmt.Errorf("grid overlap %g with a minimum radius of %gm subdivides dense circles more than %d times, raise the minimum radius or lower the overlap",
+			o.Overlap, o.MinRadius, MaxSubdivisionDepth)
+	}
+	return nil
+}
+
+// SubRadius is the radius of the seven circles a dense circle of the radius is split into
+func (o GridOptions) SubRadius(radius float64) float64 {
+	return radius / 2 * (1 + o.Overlap)
+}
+
+// SubdivisionDepth counts the circle sizes from radius down to MinRadius, i.e. the circle
+// itself and each level of subdivision a circle dense all the way down goes through.
+// It stops counting past MaxSubdivisionDepth.
+func (o GridOptions) SubdivisionDepth(radius float64) int {
+	depth := 0
+	for r := radius; r >= o.MinRadius && depth <= MaxSubdivisionDepth; r = o.SubRadius(r) {
+		depth++
+	}
+	return depth
+}
+
+// LngMeterToDegree converts meters to longitude degrees at the latitude, where
+// a degree of longitude is shorter than one of latitude by the latitude's cosine
+func LngMeterToDegree(lat float64) float64 {
+	return LatMeterToDegree / math.Cos(lat*math.Pi/180)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestGridOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		grid    GridOptions
+		wantErr bool
+	}{
+		{"default", DefaultGridOptions(), false},
+		{"no overlap", GridOptions{Overlap: 0, MinRadius: 50}, false},
+		{"negative overlap", GridOptions{Overlap: -0.1, MinRadius: 50}, true},
+		{"overlap of a whole radius", GridOptions{Overlap: 1, MinRadius: 50}, true},
+		{"no minimum radius", GridOptions{Overlap: 0.1}, true},
+		{"minimum radius above the max", GridOptions{Overlap: 0.1, MinRadius: MaxSearchRadius + 1}, true},
+		{"small minimum radius", GridOptions{Overlap: 0.1, MinRadius: 1}, false},
+		{"minimum radius too small to reach", GridOptions{Overlap: 0.1, MinRadius: 0.1}, true},
+		{"large overlap with a large minimum radius", GridOptions{Overlap: 0.5, MinRadius: 500}, false},
+		{"large overlap with the default minimum radius", GridOptions{Overlap: 0.5, MinRadius: 50}, true},
+		{"overlap close to a whole radius", GridOptions{Overlap: 0.99, MinRadius: 50}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.grid.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
\ No newline at end of file
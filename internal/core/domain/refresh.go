@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// SearchCircle is a circle crawled earlier, as stored with its search results
+type SearchCircle struct {
+	ID        string
+	Category  string
+	Circle    Circle
+	FieldMask string   // Profile the circle was crawled with, empty for every field
+	PlaceIDs  []string // Places the last fetch returned
+	FetchedAt time.Time // Zero for results saved before fetch times were recorded
+}
+
+// Saturated reports whether the search hit MaxResultsPerReq, so the circle may hold more places
+func (c SearchCircle) Saturated() bool {
+	return len(c.PlaceIDs) >= MaxResultsPerReq
+}
+
+// RefreshReport summarizes a refresh of stale circles
+type RefreshReport struct {
+	Circles         int // Stale circles picked for the refresh
+	Refreshed       int
+	Failed          int
+	PlacesAdded     int // Places returned now that the previous fetch didn't return
+	PlacesRemoved   int // Places the previous fetch returned that are gone now
+	Saturated       int // Refreshed circles that now hit MaxResultsPerReq and may hide new places
+	BudgetExhausted bool
+}
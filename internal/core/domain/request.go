@@ -1,7 +1,8 @@
-package domain
-
-type RequestParams struct {
-	Types  []string         // For Nearby Search
-	Query  string           // For Text Search
-	Circle Circle    // For location-based searches
+package domain
+
+type RequestParams struct {
+	Types  []string         // For Nearby Search
+	Query  string           // For Text Search
+	Circle Circle    // For location-based searches
+	PageToken string // For the next page of a Text Search
 }
\ No newline at end of file
@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+)
+
+// Aspects covered by the review analysis
+const (
+	AspectFood        = "food"
+	AspectService     = "service"
+	AspectPrice       = "price"
+	AspectCleanliness = "cleanliness"
+	AspectWaitTime    = "wait_time"
+)
+
+// ReviewSummary is the offline aspect analysis of all reviews of a place.
+type ReviewSummary struct {
+	PlaceID     string            `db:"place_id"`
+	ReviewCount int               `db:"review_count"`
+	Aspects     []AspectSentiment `db:"-"`
+	TopPhrases  []PhraseCount     `db:"-"`
+	Highlights  []string          `db:"-"` // Short labels such as "cheap" or "slow service"
+	AnalyzedAt  time.Time         `db:"analyzed_at"`
+}
+
+// AspectSentiment aggregates the sentiment of review clauses about one aspect.
+type AspectSentiment struct {
+	Aspect   string
+	Mentions int
+	Positive int
+	Negative int
+	Score    float64 // (Positive - Negative) / (Positive + Negative), 0 if neutral
+}
+
+// PhraseCount is a frequent phrase found in the reviews of a place.
+type PhraseCount struct {
+	Phrase string
+	Count  int
+}
@@ -0,0 +1,18 @@
+package domain
+
+// PlaceFeatures is a place together with the attributes used to compare it with other places
+type PlaceFeatures struct {
+	Place          Place
+	Types          []string
+	Dishes         []string
+	Keywords       []string // Review highlights and frequent phrases
+	DistanceMeters float64  // Distance from the point the features were queried around
+}
+
+// SimilarPlace is a place recommended as an alternative to another one
+type SimilarPlace struct {
+	Place
+	Similarity     float64  // 0..1, higher is more similar
+	DistanceMeters float64
+	Reasons        []string // What the two places have in common
+}
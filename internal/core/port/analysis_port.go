@@ -0,0 +1,18 @@
+package port
+
+import (
+	"context"
+)
+
+type ReviewAnalysisServicePort interface {
+	AnalyzeReviews(ctx context.Context) error
+}
+
+type DishExtractionServicePort interface {
+	ExtractDishes(ctx context.Context) error
+}
+
+// DishExtractor maps free text (place names, reviews, search strings) to canonical dish names
+type DishExtractor interface {
+	ExtractDishes(text string) map[string]int
+}
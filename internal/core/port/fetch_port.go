@@ -1,17 +1,25 @@
-package port
-
-import (
-	"context"
-)
-
-type FetchAreasPort interface {
-	FetchAreas(ctx context.Context, query string) error
-}
-
-type FetchPlacesPort interface {
-	FetchPlaces(ctx context.Context, minLat, maxLat, minLng, maxLng float64, category string) error
-}
-
-type FetchImagesPort interface {
-	FetchImages(ctx context.Context) error
-}
+package port
+
+import (
+	"context"
+
+	"wheretoeat/internal/core/domain"
+)
+
+type FetchAreasPort interface {
+	FetchAreas(ctx context.Context, query string) error
+}
+
+type FetchPlacesPort interface {
+	FetchPlaces(ctx context.Context, minLat, maxLat, minLng, maxLng float64, boundary domain.Boundary, grid domain.GridOptions, category string) (*domain.CrawlReport, error)
+	PlanFetchPlaces(ctx context.Context, minLat, maxLat, minLng, maxLng float64, boundary domain.Boundary, grid domain.GridOptions, fieldMask domain.FieldMask, category string) (*domain.CrawlPlan, error)
+	ResumeFetchPlaces(ctx context.Context, jobID string) (*domain.CrawlReport, error)
+}
+
+type ImportOSMPort interface {
+	ImportOSM(ctx context.Context, path string, fallbackCategory string) error
+}
+
+type FetchImagesPort interface {
+	FetchImages(ctx context.Context) error
+}
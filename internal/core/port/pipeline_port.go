@@ -1,9 +1,9 @@
-package port
-
-import (
-	"context"
-)
-
-type PlacesETLServicePort interface {
-	SearchResultsToPostgres(ctx context.Context) error
-}
+package port
+
+import (
+	"context"
+)
+
+type PlacesETLServicePort interface {
+	SearchResultsToPostgres(ctx context.Context) error
+}
\ No newline at end of file
@@ -1,10 +1,23 @@
-package port
-
-import (
-	"context"
-	"wheretoeat/internal/core/domain"
-)
-
-type PlacesAPIPort interface {
-	FetchPlaces(ctx context.Context, params domain.RequestParams) ([]interface{}, error)
-}
+package port
+
+import (
+	"context"
+	"wheretoeat/internal/core/domain"
+)
+
+type PlacesAPIPort interface {
+	FetchPlaces(ctx context.Context, params domain.RequestParams) ([]domain.PlaceResult, error)
+	FieldMask() domain.FieldMask // Fields every returned place was requested with
+}
+
+// Place Details requests, used to refresh single places without searching again
+type PlaceDetailsAPIPort interface {
+	FetchPlaceDetails(ctx context.Context, placeID string) (*domain.Place, error)
+	FieldMask() domain.FieldMask // Fields every returned place was requested with
+}
+
+// Paginated Text Search, used to crawl places that only turn up for a query
+type TextSearchAPIPort interface {
+	SearchText(ctx context.Context, params domain.RequestParams) (places []domain.PlaceResult, nextPageToken string, err error)
+	FieldMask() domain.FieldMask // Fields every returned place was requested with
+}
\ No newline at end of file
@@ -1,40 +1,95 @@
-package port
-
-import (
-	"context"
-
-	"wheretoeat/internal/core/domain"
-
-	"go.mongodb.org/mongo-driver/bson"
-)
-
-// Raw data from Google Places API are stored in SearchResultsRepository
-type SearchResultsRepository interface {
-	SaveSearchResults(ctx context.Context, category string, circle domain.Circle, places []interface{}) error
-	AreaHasBeenScanned(ctx context.Context, category string, circle domain.Circle) (bool, error)
-	GetNumPlaces(ctx context.Context, category string, circle domain.Circle) (int64, error) // avoid fetching area of same circle again
-}
-
-type PlacesRepository interface {
-	SavePlace(ctx context.Context, place domain.Place) error
-	SaveBatch(ctx context.Context, places []domain.Place, photos []domain.Photo, reviews []domain.Review, openingHours []struct {
-		PlaceID string
-		Type    string
-		Periods string
-	}, placeTypes []struct {
-		PlaceID string
-		Type    string
-	}) error
-	GetPhotos(ctx context.Context, limit, offset int) ([]domain.Photo, error)
-	GetNearbyPlaces(ctx context.Context, category string, circle domain.Circle, searchString string) ([]domain.Place, error)
-	UpdatePhotoURL(ctx context.Context, imgURL, placeID, photoURL string) error
-}
-
-type CategoriesRepository interface {
-	GetCategoryTypes(ctx context.Context, category string) ([]string, error)
-}
-
-type AreasRepository interface {
-	SaveArea(ctx context.Context, area bson.M) error
-	GetAreaByPlaceID(ctx context.Context, placeID string) (bson.M, error)
-}
+package port
+
+import (
+	"context"
+	"time"
+
+	"wheretoeat/internal/core/domain"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Raw data from Google Places API are stored in SearchResultsRepository
+type SearchResultsRepository interface {
+	SaveSearchResults(ctx context.Context, category string, circle domain.Circle, fieldMask domain.FieldMask, places []domain.PlaceResult) error
+	AreaHasBeenScanned(ctx context.Context, category string, circle domain.Circle, boundary domain.Boundary, fieldLevel int) (bool, error)
+	GetNumPlaces(ctx context.Context, category string, circle domain.Circle) (int64, error) // avoid fetching area of same circle again
+	SaveImportedPlaces(ctx context.Context, provider, source, category string, places []interface{}) error
+	DeleteImportedPlaces(ctx context.Context, provider, source string) error
+	SaveTextSearchResults(ctx context.Context, query, category string, circle domain.Circle, fieldMask domain.FieldMask, places []domain.PlaceResult) error
+	GetTextSearchNumPlaces(ctx context.Context, query string, circle domain.Circle, fieldLevel int) (int64, bool, error)
+	GetStaleCircles(ctx context.Context, category string, fieldMask domain.FieldMask, olderThan time.Time, limit int) ([]domain.SearchCircle, error)
+	GetSearchCircles(ctx context.Context, category string) ([]domain.SearchCircle, error)
+	UpdateSearchResults(ctx context.Context, id string, places []domain.PlaceResult) error
+}
+
+type PlacesRepository interface {
+	SavePlace(ctx context.Context, place domain.Place) error
+	SaveBatch(ctx context.Context, places []domain.Place, photos []domain.Photo, reviews []domain.Review, openingHours []struct {
+		PlaceID string
+		Type    string
+		Periods string
+	}, placeTypes []struct {
+		PlaceID string
+		Type    string
+	}, placeCategories []struct {
+		PlaceID  string
+		Category string
+	}) error
+	GetPhotos(ctx context.Context, limit, offset int) ([]domain.Photo, error)
+	GetNearbyPlaces(ctx context.Context, category, district string, circle domain.Circle, searchString string, dishes []string) ([]domain.Place, error)
+	GetPlace(ctx context.Context, placeID string) (*domain.Place, error)
+	PlaceExists(ctx context.Context, placeID string) (bool, error)
+	ListPlaces(ctx context.Context, limit, offset int) ([]domain.Place, error)
+	GetPlaceFeatures(ctx context.Context, placeID string) (*domain.PlaceFeatures, error)
+	GetNearbyPlaceFeatures(ctx context.Context, circle domain.Circle, limit int) ([]domain.PlaceFeatures, error)
+	UpdatePhotoURL(ctx context.Context, imgURL, placeID, photoURL string) error
+	UpsertPlaceDetails(ctx context.Context, place domain.Place) error
+	RecordPlaceView(ctx context.Context, placeID string) error
+	GetMostViewedPlaceIDs(ctx context.Context, limit int) ([]string, error)
+}
+
+type ReviewsRepository interface {
+	GetReviews(ctx context.Context, placeID string) ([]domain.Review, error)
+	GetReviewedPlaceIDs(ctx context.Context) ([]string, error)
+	SaveReviewSummary(ctx context.Context, summary domain.ReviewSummary) error
+	GetReviewSummary(ctx context.Context, placeID string) (*domain.ReviewSummary, error)
+}
+
+type DishesRepository interface {
+	SavePlaceDishes(ctx context.Context, placeID string, dishes []domain.PlaceDish) error
+}
+
+type CategoriesRepository interface {
+	GetCategoryTypes(ctx context.Context, category string) ([]string, error)
+	ListCategories(ctx context.Context) ([]string, error)
+}
+
+type AreasRepository interface {
+	SaveArea(ctx context.Context, area bson.M) error
+	GetAreaByPlaceID(ctx context.Context, placeID string) (bson.M, error)
+	GetAreaByName(ctx context.Context, name string) (*domain.Area, error)
+	ListAreas(ctx context.Context) ([]domain.Area, error)
+	SearchAreas(ctx context.Context, text string) ([]domain.Area, error)
+	SetAreaBoundary(ctx context.Context, placeID string, boundary domain.Boundary) error
+	UpsertArea(ctx context.Context, area domain.Area) error
+	SetAreaParent(ctx context.Context, placeID, parentID string) error
+}
+
+// Places API usage per day, used to enforce crawl budgets across runs
+type APIUsageRepository interface {
+	GetUsage(ctx context.Context, day string) (domain.APIUsage, error)
+	// ReserveUsage atomically adds one request of sku to the usage of day unless that would go
+	// over maxRequests or maxCost, where 0 means no cap, and reports whether it was added
+	ReserveUsage(ctx context.Context, day string, sku domain.SKU, cost float64, maxRequests int, maxCost float64) (bool, error)
+}
+
+// Crawl jobs and their frontier of circles, so interrupted crawls can be resumed
+type CrawlJobsRepository interface {
+	CreateJob(ctx context.Context, job domain.CrawlJob) (string, error)
+	GetJob(ctx context.Context, jobID string) (*domain.CrawlJob, error)
+	UpdateJobStatus(ctx context.Context, jobID string, status string) error
+	AddCircles(ctx context.Context, jobID string, circles []domain.Circle) error
+	MarkCircle(ctx context.Context, jobID string, circle domain.Circle, status string, numPlaces int64, errMsg string) error
+	GetUnfinishedCircles(ctx context.Context, jobID string) ([]domain.CrawlCircle, error)
+}
\ No newline at end of file
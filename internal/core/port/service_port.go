@@ -1,10 +1,26 @@
-package port
-
-import (
-	"context"
-	"wheretoeat/internal/core/domain"
-)
-
-type GetPlacesServicePort interface {
-	GetNearbyPlaces(ctx context.Context, lat, lng, radius float64, category string, searchString string) ([]domain.Place, error)
+package port
+
+import (
+	"context"
+	"wheretoeat/internal/core/domain"
+)
+
+type GetPlacesServicePort interface {
+	GetNearbyPlaces(ctx context.Context, lat, lng, radius float64, category, district string, searchString string) ([]domain.Place, error)
+}
+
+type GetReviewsServicePort interface {
+	GetReviews(ctx context.Context, placeID string) ([]domain.Review, error)
+}
+
+type GetPlaceServicePort interface {
+	GetPlace(ctx context.Context, placeID string) (*domain.Place, error)
+}
+
+type GetSimilarPlacesServicePort interface {
+	GetSimilarPlaces(ctx context.Context, placeID string, maxDistance float64, limit int) ([]domain.SimilarPlace, error)
+}
+
+type GetCoverageServicePort interface {
+	GetCoverage(ctx context.Context, category, areaName string) (*domain.CoverageReport, error)
 }
\ No newline at end of file
@@ -1,9 +1,9 @@
-package port
-
-import (
-	"context"
-)
-
-type Storage interface {
-	Upload(ctx context.Context, src string, dst string) (string, error)
+package port
+
+import (
+	"context"
+)
+
+type Storage interface {
+	Upload(ctx context.Context, src string, dst string) (string, error)
 }
\ No newline at end of file